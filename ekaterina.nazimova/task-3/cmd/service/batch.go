@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/batch"
+	"github.com/UwUshkin/task-3/internal/processor"
+)
+
+const defaultBatchConfigPath = "batch.yaml"
+
+var errBatchFailed = errors.New("batch finished with failed jobs")
+
+func runBatch(args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	configPath := flags.String("config", defaultBatchConfigPath, "Path to the YAML batch configuration file")
+	workers := flags.Int("workers", 0, "Number of concurrent jobs (overrides the config)")
+
+	_ = flags.Parse(args)
+
+	plan, err := batch.Load(*configPath)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrConfig, fmt.Errorf("fatal error loading batch config '%s': %w", *configPath, err))
+	}
+
+	if *workers > 0 {
+		plan.Workers = *workers
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := 0
+
+	for _, result := range batch.Run(ctx, plan, processor.Run) {
+		if result.Err != nil {
+			failed++
+
+			fmt.Fprintf(os.Stdout, "FAIL %s: %v\n", result.Job.Name, result.Err)
+
+			continue
+		}
+
+		fmt.Fprintf(os.Stdout, "ok   %s -> %s (%s)\n",
+			result.Job.Name, result.Job.Config.OutputFile, result.Duration.Round(time.Millisecond))
+	}
+
+	fmt.Fprintf(os.Stdout, "%d job(s), %d failed\n", len(plan.Jobs), failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", errBatchFailed, failed, len(plan.Jobs))
+	}
+
+	return nil
+}
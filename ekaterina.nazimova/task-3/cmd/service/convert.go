@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/processor"
+	"github.com/UwUshkin/task-3/internal/rates"
+)
+
+var errMissingCurrency = errors.New("both -from and -to currencies are required")
+
+func runConvert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to the YAML configuration file")
+	inputPath := flags.String("input", "", "Path to the CBR XML file (defaults to input-file from the config)")
+	from := flags.String("from", "", "Source currency char code, e.g. USD")
+	target := flags.String("to", "", "Target currency char code, e.g. EUR")
+	amountStr := flags.String("amount", "1", "Amount of the source currency")
+	decoding := addDecodeFlags(flags)
+
+	_ = flags.Parse(args)
+
+	if *from == "" || *target == "" {
+		return apperr.Wrap(apperr.ErrUsage, errMissingCurrency)
+	}
+
+	amount, err := data.ParseCurrencyValue(*amountStr)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrUsage, fmt.Errorf("parsing amount %q: %w", *amountStr, err))
+	}
+
+	opts, err := decoding.options()
+	if err != nil {
+		return err
+	}
+
+	if *inputPath == "" {
+		cfg, err := loadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+
+		if opts, err = processor.DecoderOptions(cfg); err != nil {
+			return apperr.Wrap(apperr.ErrConfig, err)
+		}
+
+		*inputPath = cfg.InputFile
+	}
+
+	converter, err := rates.Load(*inputPath, opts)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("fatal error loading rates: %w", err))
+	}
+
+	converted, err := converter.Convert(amount, *from, *target)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrTransform, fmt.Errorf("converting %s to %s: %w", *from, *target, err))
+	}
+
+	fmt.Fprintf(os.Stdout, "%v %s = %v %s (%s)\n", amount, *from, converted, *target, converter.Date())
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/charset"
+	"github.com/UwUshkin/task-3/internal/config"
+	"github.com/UwUshkin/task-3/internal/numlocale"
+	"github.com/UwUshkin/task-3/internal/processor"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+// decodeFlags are the input-encoding, input-locale and decode-mode settings
+// for subcommands that read XML without a config file.
+type decodeFlags struct {
+	encoding *string
+	locale   *string
+	mode     *string
+}
+
+func addDecodeFlags(flags *flag.FlagSet) decodeFlags {
+	defaults := config.Default()
+
+	return decodeFlags{
+		encoding: flags.String("encoding", defaults.InputEncoding,
+			"Input charset, overriding the XML declaration and BOM"),
+		locale: flags.String("locale", defaults.InputLocale,
+			"Number locale of the input ("+strings.Join(numlocale.Names(), ", ")+")"),
+		mode: flags.String("decode-mode", defaults.DecodeMode,
+			"strict stops at the first malformed Valute, lenient skips it with a warning"),
+	}
+}
+
+func (d decodeFlags) options() (xmldecoder.Options, error) {
+	if *d.encoding != "" {
+		if _, err := charset.Lookup(*d.encoding); err != nil {
+			return xmldecoder.Options{}, apperr.Wrap(apperr.ErrUsage, fmt.Errorf("-encoding: %w", err))
+		}
+	}
+
+	cfg := config.Default()
+	cfg.InputEncoding = *d.encoding
+	cfg.InputLocale = *d.locale
+	cfg.DecodeMode = *d.mode
+
+	opts, err := processor.DecoderOptions(cfg)
+	if err != nil {
+		return xmldecoder.Options{}, apperr.Wrap(apperr.ErrUsage, err)
+	}
+
+	return opts, nil
+}
+
+// logIssues reports the Valute elements skipped in lenient mode.
+func logIssues(issues []xmldecoder.Issue) {
+	for _, issue := range issues {
+		log.Printf("skipped %s: Valute[%d] (ID %s): %s", issue.Source, issue.Index, issue.ID, issue.Reason)
+	}
+}
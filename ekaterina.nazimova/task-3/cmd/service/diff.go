@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/diff"
+	"github.com/UwUshkin/task-3/internal/output"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+const diffInputs = 2
+
+var errDiffInputs = errors.New("diff expects exactly two XML inputs: <previous.xml> <current.xml>")
+
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	outputPath := flags.String("output", "", "Path of the report file (defaults to stdout)")
+	format := flags.String("format", "", "Report format (defaults to the output file extension, then json)")
+	decoding := addDecodeFlags(flags)
+
+	_ = flags.Parse(args)
+
+	if flags.NArg() != diffInputs {
+		return apperr.Wrap(apperr.ErrUsage, errDiffInputs)
+	}
+
+	opts, err := decoding.options()
+	if err != nil {
+		return err
+	}
+
+	previousPath, currentPath := flags.Arg(0), flags.Arg(1)
+
+	previous, previousIssues, err := xmldecoder.DecodeWithIssues(previousPath, opts)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("decoding previous XML: %w", err))
+	}
+
+	current, currentIssues, err := xmldecoder.DecodeWithIssues(currentPath, opts)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("decoding current XML: %w", err))
+	}
+
+	logIssues(append(previousIssues, currentIssues...))
+
+	return writeReport(*outputPath, *format, diff.Compare(previous, current).Table())
+}
+
+func writeReport(outputPath, format string, table *output.Table) error {
+	if outputPath != "" {
+		if err := output.WriteFile(outputPath, format, table); err != nil {
+			return apperr.Wrap(apperr.ErrEncode, fmt.Errorf("saving report: %w", err))
+		}
+
+		return nil
+	}
+
+	resolved, err := output.Resolve(format, outputPath)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrUsage, fmt.Errorf("resolving output format: %w", err))
+	}
+
+	if err := output.Encode(os.Stdout, resolved, table); err != nil {
+		return apperr.Wrap(apperr.ErrEncode, fmt.Errorf("writing report: %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+const (
+	errorFormatFlag = "error-format"
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+var errInvalidErrorFormat = errors.New("unsupported -error-format")
+
+type errorReport struct {
+	Stage    string `json:"stage"`
+	ExitCode int    `json:"exit_code"`
+	Message  string `json:"message"`
+	Source   string `json:"source,omitempty"`
+	Index    *int   `json:"index,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Offset   *int64 `json:"offset,omitempty"`
+}
+
+// extractErrorFormat removes -error-format from anywhere in args so that it
+// can be combined with every subcommand.
+func extractErrorFormat(args []string) (string, []string, error) {
+	format := errorFormatText
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != errorFormatFlag {
+			rest = append(rest, arg)
+
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return errorFormatText, nil, apperr.Wrap(apperr.ErrUsage,
+					fmt.Errorf("%w: missing value", errInvalidErrorFormat))
+			}
+
+			i++
+			value = args[i]
+		}
+
+		format = value
+	}
+
+	if format != errorFormatText && format != errorFormatJSON {
+		return errorFormatText, nil, apperr.Wrap(apperr.ErrUsage,
+			fmt.Errorf("%w %q (expected %s or %s)", errInvalidErrorFormat, format, errorFormatText, errorFormatJSON))
+	}
+
+	return format, rest, nil
+}
+
+func reportError(writer io.Writer, format string, err error) {
+	if format != errorFormatJSON {
+		fmt.Fprintf(writer, "%v\n", err)
+
+		return
+	}
+
+	report := errorReport{
+		Stage:    "internal",
+		ExitCode: apperr.ExitCode(err),
+		Message:  err.Error(),
+		Source:   "",
+		Index:    nil,
+		Line:     0,
+		Column:   0,
+		Offset:   nil,
+	}
+
+	if stage := apperr.Stage(err); stage != nil {
+		report.Stage = stage.Error()
+	}
+
+	var decodeErr *xmldecoder.DecodeError
+	if errors.As(err, &decodeErr) {
+		report.Source = decodeErr.Source
+		report.Index = &decodeErr.Index
+		report.Line = decodeErr.Line
+		report.Column = decodeErr.Column
+		report.Offset = &decodeErr.Offset
+	}
+
+	encoded, marshalErr := json.Marshal(report)
+	if marshalErr != nil {
+		fmt.Fprintf(writer, "%v\n", err)
+
+		return
+	}
+
+	fmt.Fprintf(writer, "%s\n", encoded)
+}
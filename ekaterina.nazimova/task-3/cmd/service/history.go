@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/history"
+	"github.com/UwUshkin/task-3/internal/source"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+const defaultHistoryDir = "history"
+
+var (
+	errNoIngestPatterns = errors.New("at least one XML input is required")
+	errMissingCharCode  = errors.New("-code is required")
+	errMissingDate      = errors.New("either -date or -from/-to is required")
+)
+
+func runIngest(args []string) error {
+	flags := flag.NewFlagSet("ingest", flag.ExitOnError)
+	storeDir := flags.String("store", defaultHistoryDir, "Directory of the local history store")
+	decoding := addDecodeFlags(flags)
+
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return apperr.Wrap(apperr.ErrUsage, errNoIngestPatterns)
+	}
+
+	opts, err := decoding.options()
+	if err != nil {
+		return err
+	}
+
+	store, err := history.Open(*storeDir)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrUsage, fmt.Errorf("fatal error opening history store: %w", err))
+	}
+
+	for _, spec := range flags.Args() {
+		documents, err := source.Resolve(spec)
+		if err != nil {
+			return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("resolving input %q: %w", spec, err))
+		}
+
+		for _, document := range documents {
+			valCurs, issues, err := xmldecoder.DecodeDocumentWithIssues(document, opts)
+			if err != nil {
+				return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("decoding XML: %w", err))
+			}
+
+			logIssues(issues)
+
+			date, err := store.Put(valCurs)
+			if err != nil {
+				return apperr.Wrap(apperr.ErrEncode, fmt.Errorf("storing %q: %w", document.Name, err))
+			}
+
+			fmt.Fprintf(os.Stdout, "ingested %s (%s, %d currencies)\n",
+				document.Name, date.Format(data.DateLayout), len(valCurs.Valutes))
+		}
+	}
+
+	return nil
+}
+
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	storeDir := flags.String("store", defaultHistoryDir, "Directory of the local history store")
+	charCode := flags.String("code", "", "Currency char code, e.g. USD")
+	day := flags.String("date", "", "Single day to query, DD.MM.YYYY")
+	fromStr := flags.String("from", "", "Start of the date range, DD.MM.YYYY")
+	toStr := flags.String("to", "", "End of the date range, DD.MM.YYYY")
+
+	_ = flags.Parse(args)
+
+	if *charCode == "" {
+		return apperr.Wrap(apperr.ErrUsage, errMissingCharCode)
+	}
+
+	if *day != "" {
+		*fromStr, *toStr = *day, *day
+	}
+
+	from, to, err := parseDateRange(*fromStr, *toStr)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrUsage, err)
+	}
+
+	store, err := history.Open(*storeDir)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrUsage, fmt.Errorf("fatal error opening history store: %w", err))
+	}
+
+	points, err := store.Range(*charCode, from, to)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("querying history for %s: %w", *charCode, err))
+	}
+
+	jsonData, err := json.MarshalIndent(points, "", "  ")
+	if err != nil {
+		return apperr.Wrap(apperr.ErrEncode, fmt.Errorf("marshalling history to JSON: %w", err))
+	}
+
+	fmt.Fprintf(os.Stdout, "%s\n", jsonData)
+
+	return nil
+}
+
+func parseDateRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	if fromStr == "" && toStr == "" {
+		return time.Time{}, time.Time{}, errMissingDate
+	}
+
+	from, to := time.Time{}, time.Now()
+
+	var err error
+
+	if fromStr != "" {
+		if from, err = data.ParseDate(fromStr); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing -from: %w", err)
+		}
+	}
+
+	if toStr != "" {
+		if to, err = data.ParseDate(toStr); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing -to: %w", err)
+		}
+	}
+
+	return from, to, nil
+}
@@ -5,26 +5,126 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/UwUshkin/task-3/internal/apperr"
 	"github.com/UwUshkin/task-3/internal/config"
 	"github.com/UwUshkin/task-3/internal/processor"
+	"github.com/UwUshkin/task-3/internal/watch"
 )
 
+const defaultConfigPath = "config.yaml"
+
 func main() {
-	var configPath string
+	errorFormat, args, err := extractErrorFormat(os.Args[1:])
+	if err == nil {
+		err = run(args)
+	}
+
+	if err != nil {
+		reportError(os.Stderr, errorFormat, err)
+		os.Exit(apperr.ExitCode(err))
+	}
+}
 
-	flag.StringVar(&configPath, "config", "config.yaml", "Path to the YAML configuration file")
-	flag.Parse()
+func run(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "batch":
+			return runBatch(args[1:])
+		case "convert":
+			return runConvert(args[1:])
+		case "ingest":
+			return runIngest(args[1:])
+		case "history":
+			return runHistory(args[1:])
+		case "serve":
+			return runServe(args[1:])
+		case "diff":
+			return runDiff(args[1:])
+		case "reverse":
+			return runReverse(args[1:])
+		case "stats":
+			return runStats(args[1:])
+		case "validate":
+			return runValidate(args[1:])
+		}
+	}
 
-	cfg, err := config.LoadConfig(configPath)
+	return runExport(args)
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("service", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to the YAML configuration file")
+	printConfig := flags.Bool("print-config", false, "Print the effective configuration and exit")
+	fields := flags.String("fields", "", "Comma-separated list of output fields (overrides the config)")
+	sortSpec := flags.String("sort", "", "Sort spec such as 'value:desc,char_code:asc' (overrides the config)")
+	watchMode := flags.Bool("watch", false, "Regenerate the output whenever the input or config file changes")
+	watchInterval := flags.Duration("watch-interval", watch.DefaultInterval, "How often to poll watched files")
+	debounce := flags.Duration("debounce", watch.DefaultDebounce, "Quiet period to wait for after a change")
+
+	var expressions []string
+
+	flags.Func("filter", "Filter expression such as 'unit_rate > 1' (repeatable, overrides the config)",
+		func(expression string) error {
+			expressions = append(expressions, expression)
+
+			return nil
+		})
+
+	_ = flags.Parse(args)
+
+	opts := exportOptions{
+		configPath:  *configPath,
+		fields:      *fields,
+		sortSpec:    *sortSpec,
+		expressions: expressions,
+	}
+
+	cfg, err := opts.load()
 	if err != nil {
-		wrappedErr := fmt.Errorf("fatal error loading config file '%s': %w", configPath, err)
-		fmt.Fprintf(os.Stderr, "%v\n", wrappedErr)
-		os.Exit(1)
+		return err
+	}
+
+	if *printConfig {
+		return cfg.Dump(os.Stdout)
 	}
 
-	if err := processor.ProcessAndSave(cfg.InputFile, cfg.OutputFile); err != nil {
-		wrappedErr := fmt.Errorf("fatal error during data processing: %w", err)
-		fmt.Fprintf(os.Stderr, "%v\n", wrappedErr)
-		os.Exit(1)
+	if *watchMode {
+		return runWatch(opts, cfg, *watchInterval, *debounce)
 	}
+
+	if err := processor.ProcessAndSave(cfg); err != nil {
+		return fmt.Errorf("fatal error during data processing: %w", err)
+	}
+
+	return nil
+}
+
+func applyExportFlags(cfg *config.Config, fields string, expressions []string) error {
+	if fields != "" {
+		cfg.Fields = config.SplitList(fields, ",")
+	}
+
+	if len(expressions) > 0 {
+		cfg.Filters.Expressions = expressions
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return apperr.Wrap(apperr.ErrConfig, fmt.Errorf("invalid command-line options: %w", err))
+	}
+
+	if err := cfg.RequireOutput(); err != nil {
+		return apperr.Wrap(apperr.ErrConfig, err)
+	}
+
+	return nil
+}
+
+func loadConfig(configPath string) (*config.Config, error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.ErrConfig, fmt.Errorf("fatal error loading config file '%s': %w", configPath, err))
+	}
+
+	return cfg, nil
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/cbrxml"
+	"github.com/UwUshkin/task-3/internal/output"
+	"github.com/UwUshkin/task-3/internal/source"
+)
+
+var errMissingReverseInput = errors.New("-input is required (use - for stdin)")
+
+func runReverse(args []string) error {
+	flags := flag.NewFlagSet("reverse", flag.ExitOnError)
+	inputPath := flags.String("input", "",
+		"JSON, NDJSON or YAML file written by the service with at least the fields "+cbrxml.RequiredFields+
+			" (- for stdin)")
+	format := flags.String("format", "", "Input format (defaults to the input file extension, then json)")
+	outputPath := flags.String("output", "", "Path of the ValCurs XML file (defaults to stdout)")
+	date := flags.String("date", "", "ValCurs Date, DD.MM.YYYY (defaults to the records' date field)")
+	name := flags.String("name", cbrxml.MarketName, "ValCurs name attribute")
+
+	_ = flags.Parse(args)
+
+	if *inputPath == "" {
+		return apperr.Wrap(apperr.ErrUsage, errMissingReverseInput)
+	}
+
+	resolved, err := output.Resolve(*format, *inputPath)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrUsage, fmt.Errorf("resolving input format: %w", err))
+	}
+
+	var input io.Reader = os.Stdin
+
+	if *inputPath != source.Stdin {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("opening %q: %w", *inputPath, err))
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	valCurs, err := cbrxml.Read(input, resolved, *date)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("reading %s records: %w", resolved, err))
+	}
+
+	valCurs.Name = *name
+
+	if *outputPath == "" {
+		err = cbrxml.Encode(os.Stdout, valCurs)
+	} else {
+		err = cbrxml.WriteFile(*outputPath, valCurs)
+	}
+
+	if err != nil {
+		return apperr.Wrap(apperr.ErrEncode, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/processor"
+	"github.com/UwUshkin/task-3/internal/server"
+)
+
+const (
+	defaultServeAddr  = "127.0.0.1:8080"
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 10 * time.Second
+)
+
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to the YAML configuration file")
+	addr := flags.String("addr", defaultServeAddr, "Address to listen on")
+
+	_ = flags.Parse(args)
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	opts, err := processor.DecoderOptions(cfg)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrConfig, err)
+	}
+
+	srv, err := server.New(cfg.InputFile, opts)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("fatal error loading rates: %w", err))
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		log.Printf("serving rates from %q on http://%s", cfg.InputFile, *addr)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("serving HTTP: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("shutting down HTTP server: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/stats"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+var errNoStatsInputs = errors.New("at least one XML input is required")
+
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	window := flags.Int("window", stats.DefaultWindow, "Number of days in the moving average window")
+	series := flags.Bool("series", false, "Export the daily moving average series instead of the summary")
+	outputPath := flags.String("output", "", "Path of the report file (defaults to stdout)")
+	format := flags.String("format", "", "Report format (defaults to the output file extension, then json)")
+	decoding := addDecodeFlags(flags)
+
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return apperr.Wrap(apperr.ErrUsage, errNoStatsInputs)
+	}
+
+	if *window <= 0 {
+		return apperr.Wrap(apperr.ErrUsage, fmt.Errorf("%w, got %d", stats.ErrInvalidWindow, *window))
+	}
+
+	opts, err := decoding.options()
+	if err != nil {
+		return err
+	}
+
+	rates := stats.NewAccumulator()
+
+	for _, spec := range flags.Args() {
+		issues, err := xmldecoder.Walk(spec, opts, rates.Add)
+		if err != nil {
+			return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("decoding XML: %w", err))
+		}
+
+		logIssues(issues)
+	}
+
+	report, err := rates.Report(*window)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrTransform, fmt.Errorf("computing statistics: %w", err))
+	}
+
+	if *series {
+		return writeReport(*outputPath, *format, report.SeriesTable())
+	}
+
+	return writeReport(*outputPath, *format, report.Table())
+}
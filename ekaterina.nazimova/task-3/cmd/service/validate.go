@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/iso4217"
+	"github.com/UwUshkin/task-3/internal/processor"
+	"github.com/UwUshkin/task-3/internal/source"
+	"github.com/UwUshkin/task-3/internal/validator"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+const ruleDecode = "decode"
+
+var errInvalidInput = errors.New("input failed validation")
+
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath, "Path to the YAML configuration file")
+	inputPath := flags.String("input", "", "CBR XML input (defaults to input-file from the config)")
+	decoding := addDecodeFlags(flags)
+
+	_ = flags.Parse(args)
+
+	opts, err := decoding.options()
+	if err != nil {
+		return err
+	}
+
+	currencies, err := iso4217.Builtin()
+	if err != nil {
+		return fmt.Errorf("loading ISO 4217 table: %w", err)
+	}
+
+	if *inputPath == "" {
+		cfg, err := loadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+
+		if opts, err = processor.DecoderOptions(cfg); err != nil {
+			return apperr.Wrap(apperr.ErrConfig, err)
+		}
+
+		*inputPath = cfg.InputFile
+		currencies = cfg.Catalog
+	}
+
+	// Report every broken Valute instead of stopping at the first one, even
+	// when the config asks for strict decoding.
+	opts.Mode = xmldecoder.ModeLenient
+
+	documents, err := source.Resolve(*inputPath)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("resolving input %q: %w", *inputPath, err))
+	}
+
+	checker := validator.New(currencies)
+	violations := 0
+
+	for _, document := range documents {
+		valCurs, issues, err := xmldecoder.DecodeDocumentWithIssues(document, opts)
+		if err != nil {
+			return apperr.Wrap(apperr.ErrDecode, fmt.Errorf("decoding XML: %w", err))
+		}
+
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stdout, "%s: Valute[%d] (ID %s): %s: %s\n",
+				issue.Source, issue.Index, issue.ID, ruleDecode, issue.Reason)
+		}
+
+		found := checker.Validate(document.Name, valCurs)
+		for _, violation := range found {
+			fmt.Fprintln(os.Stdout, violation)
+		}
+
+		violations += len(issues) + len(found)
+	}
+
+	if violations > 0 {
+		return apperr.Wrap(apperr.ErrValidation, fmt.Errorf("%w: %d violation(s) in %d document(s)",
+			errInvalidInput, violations, len(documents)))
+	}
+
+	fmt.Fprintf(os.Stdout, "%d document(s) valid\n", len(documents))
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/config"
+	"github.com/UwUshkin/task-3/internal/processor"
+	"github.com/UwUshkin/task-3/internal/source"
+	"github.com/UwUshkin/task-3/internal/watch"
+)
+
+var errInvalidInterval = errors.New("-watch-interval must be positive")
+
+type exportOptions struct {
+	configPath  string
+	fields      string
+	sortSpec    string
+	expressions []string
+}
+
+func (o exportOptions) load() (*config.Config, error) {
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.sortSpec != "" {
+		cfg.Sort = o.sortSpec
+	}
+
+	if err := applyExportFlags(cfg, o.fields, o.expressions); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// runWatch regenerates the output whenever the input or the config file
+// changes. Failed runs are logged and the previous output is left in place.
+func runWatch(opts exportOptions, cfg *config.Config, interval, debounce time.Duration) error {
+	if interval <= 0 {
+		return apperr.Wrap(apperr.ErrUsage, fmt.Errorf("%w, got %s", errInvalidInterval, interval))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	regenerate(ctx, cfg, "initial run")
+
+	poller := watch.NewPoller(func() []string { return watchedPaths(opts.configPath, cfg) }, interval, debounce)
+
+	log.Printf("watching %q and %q for changes", cfg.InputFile, opts.configPath)
+
+	poller.Run(ctx, func(changed []string) {
+		reason := "changed: " + strings.Join(changed, ", ")
+
+		for _, path := range changed {
+			if path != opts.configPath {
+				continue
+			}
+
+			reloaded, err := opts.load()
+			if err != nil {
+				log.Printf("keeping previous configuration: %v", err)
+
+				break
+			}
+
+			cfg = reloaded
+			poller.Rescan()
+		}
+
+		regenerate(ctx, cfg, reason)
+	})
+
+	log.Printf("watch stopped")
+
+	return nil
+}
+
+// watchedPaths lists the config file and every file the input spec resolves
+// to right now, so that new glob matches and files added to a watched
+// directory trigger a run as well.
+func watchedPaths(configPath string, cfg *config.Config) []string {
+	return append([]string{configPath}, source.Paths(cfg.InputFile)...)
+}
+
+func regenerate(ctx context.Context, cfg *config.Config, reason string) {
+	started := time.Now()
+
+	if err := processor.Run(ctx, cfg); err != nil {
+		log.Printf("regeneration failed (%s): %v", reason, err)
+
+		return
+	}
+
+	files := make([]string, 0, len(cfg.Outputs)+1)
+	for _, out := range cfg.ResolvedOutputs() {
+		files = append(files, out.File)
+	}
+
+	log.Printf("regenerated %s from %q in %s (%s)",
+		strings.Join(files, ", "), cfg.InputFile, time.Since(started).Round(time.Millisecond), reason)
+}
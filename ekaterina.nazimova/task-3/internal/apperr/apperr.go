@@ -0,0 +1,97 @@
+// Package apperr classifies failures by the pipeline stage they happened in
+// and maps each stage to a process exit code:
+//
+//	0  success
+//	1  any other failure
+//	2  invalid command-line usage
+//	3  config: the config file is missing, unreadable or invalid
+//	4  decode: the input cannot be opened or is not valid CBR XML
+//	5  transform: filtering, sorting, rounding or conversion failed
+//	6  encode: the output cannot be encoded or written
+//	7  validation: the input decoded but violates semantic checks
+package apperr
+
+import "errors"
+
+const (
+	ExitOK         = 0
+	ExitFailure    = 1
+	ExitUsage      = 2
+	ExitConfig     = 3
+	ExitDecode     = 4
+	ExitTransform  = 5
+	ExitEncode     = 6
+	ExitValidation = 7
+)
+
+var (
+	ErrUsage      = errors.New("usage")
+	ErrConfig     = errors.New("config")
+	ErrDecode     = errors.New("decode")
+	ErrTransform  = errors.New("transform")
+	ErrEncode     = errors.New("encode")
+	ErrValidation = errors.New("validation")
+)
+
+type stage struct {
+	err      error
+	exitCode int
+}
+
+func stages() []stage {
+	return []stage{
+		{err: ErrUsage, exitCode: ExitUsage},
+		{err: ErrConfig, exitCode: ExitConfig},
+		{err: ErrDecode, exitCode: ExitDecode},
+		{err: ErrTransform, exitCode: ExitTransform},
+		{err: ErrEncode, exitCode: ExitEncode},
+		{err: ErrValidation, exitCode: ExitValidation},
+	}
+}
+
+type stageError struct {
+	stage error
+	err   error
+}
+
+func (e *stageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stageError) Unwrap() []error {
+	return []error{e.stage, e.err}
+}
+
+// Wrap tags err with a stage sentinel without changing its message. The
+// innermost tag wins, so wrapping an already classified error is a no-op.
+func Wrap(stage, err error) error {
+	if err == nil || Stage(err) != nil {
+		return err
+	}
+
+	return &stageError{stage: stage, err: err}
+}
+
+func Stage(err error) error {
+	for _, candidate := range stages() {
+		if errors.Is(err, candidate.err) {
+			return candidate.err
+		}
+	}
+
+	return nil
+}
+
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	for _, candidate := range stages() {
+		if errors.Is(err, candidate.err) {
+			return candidate.exitCode
+		}
+	}
+
+	return ExitFailure
+}
@@ -0,0 +1,135 @@
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const dirPermissions = 0o755
+
+// Write replaces path with content so that readers only ever see the old or
+// the new file: the data goes to a temporary file in the same directory,
+// is fsynced and then renamed over path. When backups is positive the
+// previous version is kept as path.1, older ones shift to path.2 and so on.
+func Write(path string, content []byte, perm os.FileMode, backups int) (err error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return fmt.Errorf("creating directory %q: %w", dir, err)
+	}
+
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file for %q: %w", path, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = temp.Close()
+			_ = os.Remove(temp.Name())
+		}
+	}()
+
+	if _, err := temp.Write(content); err != nil {
+		return fmt.Errorf("writing temporary file %q: %w", temp.Name(), err)
+	}
+
+	if err := temp.Chmod(perm); err != nil {
+		return fmt.Errorf("setting permissions on %q: %w", temp.Name(), err)
+	}
+
+	if err := temp.Sync(); err != nil {
+		return fmt.Errorf("syncing temporary file %q: %w", temp.Name(), err)
+	}
+
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("closing temporary file %q: %w", temp.Name(), err)
+	}
+
+	if backups > 0 {
+		if err := rotate(path, backups); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("renaming %q to %q: %w", temp.Name(), path, err)
+	}
+
+	return syncDir(dir)
+}
+
+func BackupName(path string, generation int) string {
+	return path + "." + strconv.Itoa(generation)
+}
+
+// rotate shifts path.1 … path.(backups-1) one generation up and keeps the
+// current file as path.1. The current file stays in place until it is
+// replaced, so readers never observe it missing.
+func rotate(path string, backups int) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err := os.Remove(BackupName(path, backups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing oldest backup of %q: %w", path, err)
+	}
+
+	for generation := backups - 1; generation >= 1; generation-- {
+		err := os.Rename(BackupName(path, generation), BackupName(path, generation+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotating backup of %q: %w", path, err)
+		}
+	}
+
+	if err := os.Link(path, BackupName(path, 1)); err == nil {
+		return nil
+	}
+
+	return copyFile(path, BackupName(path, 1))
+}
+
+func copyFile(source, target string) (err error) {
+	input, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("opening %q for backup: %w", source, err)
+	}
+	defer input.Close()
+
+	info, err := input.Stat()
+	if err != nil {
+		return fmt.Errorf("reading %q for backup: %w", source, err)
+	}
+
+	backup, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("creating backup %q: %w", target, err)
+	}
+
+	defer func() {
+		err = errors.Join(err, backup.Close())
+	}()
+
+	if _, err := io.Copy(backup, input); err != nil {
+		return fmt.Errorf("copying %q to %q: %w", source, target, err)
+	}
+
+	return nil
+}
+
+func syncDir(dir string) error {
+	handle, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("opening directory %q: %w", dir, err)
+	}
+	defer handle.Close()
+
+	if err := handle.Sync(); err != nil {
+		return fmt.Errorf("syncing directory %q: %w", dir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/UwUshkin/task-3/internal/config"
+)
+
+type Result struct {
+	Job      Job
+	Err      error
+	Duration time.Duration
+}
+
+// Run processes jobs on a pool of workers and returns one result per job in
+// plan order. A failing job does not stop the others; once ctx is cancelled
+// jobs that have not started yet fail with ctx.Err(), and running ones see
+// the cancellation through the ctx passed to process.
+func Run(ctx context.Context, plan *Plan, process func(ctx context.Context, cfg *config.Config) error) []Result {
+	results := make([]Result, len(plan.Jobs))
+	indexes := make(chan int)
+
+	var workers sync.WaitGroup
+
+	for range min(plan.Workers, len(plan.Jobs)) {
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for index := range indexes {
+				job := plan.Jobs[index]
+
+				if err := ctx.Err(); err != nil {
+					results[index] = Result{Job: job, Err: err, Duration: 0}
+
+					continue
+				}
+
+				started := time.Now()
+				err := process(ctx, job.Config)
+				results[index] = Result{Job: job, Err: err, Duration: time.Since(started)}
+			}
+		}()
+	}
+
+	for index := range plan.Jobs {
+		indexes <- index
+	}
+
+	close(indexes)
+	workers.Wait()
+
+	return results
+}
@@ -0,0 +1,174 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/config"
+	"github.com/UwUshkin/task-3/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultPattern = "*.xml"
+
+var (
+	ErrNoJobs     = errors.New("batch config defines no jobs")
+	ErrInvalidJob = errors.New("invalid batch job")
+)
+
+type JobSpec struct {
+	InputFile    string `yaml:"input-file"`
+	OutputFile   string `yaml:"output-file"`
+	OutputFormat string `yaml:"output-format"`
+}
+
+// DirectorySpec maps every file matching Pattern in InputDir to a file of
+// the same base name in OutputDir.
+type DirectorySpec struct {
+	InputDir     string `yaml:"input-dir"`
+	OutputDir    string `yaml:"output-dir"`
+	Pattern      string `yaml:"pattern"`
+	OutputFormat string `yaml:"output-format"`
+}
+
+// File is the batch config. Defaults takes the same keys as the regular
+// config file and applies to every job; input-file and output-file are
+// set per job.
+type File struct {
+	Workers     int             `yaml:"workers"`
+	Defaults    config.Config   `yaml:"defaults"`
+	Jobs        []JobSpec       `yaml:"jobs"`
+	Directories []DirectorySpec `yaml:"directories"`
+}
+
+type Job struct {
+	Name   string
+	Config *config.Config
+}
+
+type Plan struct {
+	Workers int
+	Jobs    []Job
+}
+
+func Load(path string) (*Plan, error) {
+	batchFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading batch config %q: %w", path, err)
+	}
+	defer batchFile.Close()
+
+	file := File{Workers: runtime.NumCPU(), Defaults: *config.Default(), Jobs: nil, Directories: nil}
+
+	decoder := yaml.NewDecoder(batchFile)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unmarshalling batch config: %w", err)
+	}
+
+	return file.Plan()
+}
+
+func (f *File) Plan() (*Plan, error) {
+	if f.Workers < 1 {
+		return nil, fmt.Errorf("%w: workers must be positive, got %d", config.ErrInvalidValue, f.Workers)
+	}
+
+	if len(f.Defaults.Outputs) > 0 {
+		return nil, fmt.Errorf("%w: defaults.outputs would make every job write the same files", config.ErrInvalidValue)
+	}
+
+	// Loaded once here, the catalog is shared by every job config copied
+	// from the defaults.
+	if _, err := f.Defaults.LoadCatalog(); err != nil {
+		return nil, fmt.Errorf("%w: defaults.currency-catalog: %w", config.ErrInvalidValue, err)
+	}
+
+	specs := append([]JobSpec{}, f.Jobs...)
+
+	for _, directory := range f.Directories {
+		expanded, err := directory.expand()
+		if err != nil {
+			return nil, err
+		}
+
+		specs = append(specs, expanded...)
+	}
+
+	if len(specs) == 0 {
+		return nil, ErrNoJobs
+	}
+
+	plan := &Plan{Workers: f.Workers, Jobs: make([]Job, 0, len(specs))}
+
+	for index, spec := range specs {
+		cfg := f.Defaults
+		cfg.InputFile = spec.InputFile
+		cfg.OutputFile = spec.OutputFile
+
+		if spec.OutputFormat != "" {
+			cfg.OutputFormat = spec.OutputFormat
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("%w %d (%s): %w", ErrInvalidJob, index, spec.InputFile, err)
+		}
+
+		if err := cfg.RequireOutput(); err != nil {
+			return nil, fmt.Errorf("%w %d (%s): %w", ErrInvalidJob, index, spec.InputFile, err)
+		}
+
+		plan.Jobs = append(plan.Jobs, Job{Name: spec.InputFile, Config: &cfg})
+	}
+
+	return plan, nil
+}
+
+func (d DirectorySpec) expand() ([]JobSpec, error) {
+	if d.InputDir == "" || d.OutputDir == "" {
+		return nil, fmt.Errorf("%w: directories need both input-dir and output-dir", ErrInvalidJob)
+	}
+
+	pattern := d.Pattern
+	if pattern == "" {
+		pattern = defaultPattern
+	}
+
+	format := d.OutputFormat
+	if format == "" {
+		format = output.DefaultFormat
+	}
+
+	ext, err := output.Extension(format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidJob, d.InputDir, err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(d.InputDir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidJob, d.InputDir, err)
+	}
+
+	sort.Strings(matches)
+
+	specs := make([]JobSpec, 0, len(matches))
+
+	for _, inputPath := range matches {
+		base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+
+		specs = append(specs, JobSpec{
+			InputFile:    inputPath,
+			OutputFile:   filepath.Join(d.OutputDir, base+ext),
+			OutputFormat: format,
+		})
+	}
+
+	return specs, nil
+}
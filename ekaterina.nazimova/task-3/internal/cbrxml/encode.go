@@ -0,0 +1,116 @@
+package cbrxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/UwUshkin/task-3/internal/atomicfile"
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/numlocale"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	MarketName = "Foreign Currency Market"
+
+	header          = `<?xml version="1.0" encoding="windows-1251"?>`
+	filePermissions = 0o600
+)
+
+// decimalLocale renders numbers the way the CBR feed does: comma decimals
+// and no thousands grouping.
+func decimalLocale() numlocale.Locale {
+	return numlocale.Locale{Name: "cbr", Decimal: ',', Group: 0, AltGroups: nil, AltDecimals: nil}
+}
+
+// Marshal renders valCurs in the byte layout of the CBR daily feed: a single
+// windows-1251 line with Valute elements ordered by ID, three-digit NumCodes
+// and comma decimals. VunitRate is written only when it is known.
+func Marshal(valCurs *data.ValCurs) ([]byte, error) {
+	valutes := append(data.CurrencyList{}, valCurs.Valutes...)
+	sort.SliceStable(valutes, func(i, j int) bool {
+		return valutes[i].ID < valutes[j].ID
+	})
+
+	var document bytes.Buffer
+
+	document.WriteString(header)
+	document.WriteString(`<ValCurs Date="`)
+	escape(&document, valCurs.Date)
+	document.WriteString(`" name="`)
+	escape(&document, valCurs.Name)
+	document.WriteString(`">`)
+
+	locale := decimalLocale()
+
+	for _, valute := range valutes {
+		if valute.ID != "" {
+			document.WriteString(`<Valute ID="`)
+			escape(&document, valute.ID)
+			document.WriteString(`">`)
+		} else {
+			document.WriteString(`<Valute>`)
+		}
+
+		writeElement(&document, "NumCode", fmt.Sprintf("%03d", valute.NumCode))
+		writeElement(&document, "CharCode", valute.CharCode)
+		writeElement(&document, "Nominal", strconv.Itoa(valute.Nominal))
+		writeElement(&document, "Name", valute.Name)
+		writeElement(&document, "Value", valute.Value.Format(locale))
+
+		if !valute.UnitRate.IsZero() {
+			writeElement(&document, "VunitRate", valute.UnitRate.Format(locale))
+		}
+
+		document.WriteString(`</Valute>`)
+	}
+
+	document.WriteString(`</ValCurs>`)
+
+	encoded, err := charmap.Windows1251.NewEncoder().Bytes(document.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("encoding ValCurs as windows-1251: %w", err)
+	}
+
+	return encoded, nil
+}
+
+func Encode(writer io.Writer, valCurs *data.ValCurs) error {
+	encoded, err := Marshal(valCurs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(encoded); err != nil {
+		return fmt.Errorf("writing ValCurs XML: %w", err)
+	}
+
+	return nil
+}
+
+func WriteFile(path string, valCurs *data.ValCurs) error {
+	encoded, err := Marshal(valCurs)
+	if err != nil {
+		return err
+	}
+
+	if err := atomicfile.Write(path, encoded, filePermissions, 0); err != nil {
+		return fmt.Errorf("writing ValCurs XML %q: %w", path, err)
+	}
+
+	return nil
+}
+
+func writeElement(buffer *bytes.Buffer, name, text string) {
+	buffer.WriteString("<" + name + ">")
+	escape(buffer, text)
+	buffer.WriteString("</" + name + ">")
+}
+
+func escape(buffer *bytes.Buffer, text string) {
+	_ = xml.EscapeText(buffer, []byte(text))
+}
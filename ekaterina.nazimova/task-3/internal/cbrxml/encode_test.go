@@ -0,0 +1,134 @@
+package cbrxml
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/output"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	original, err := xmldecoder.DecodeFile(filepath.Join("..", "..", "testdata", "charsets", "windows-1251.xml"),
+		xmldecoder.DefaultOptions())
+	if err != nil {
+		t.Fatalf("decoding the original feed: %v", err)
+	}
+
+	fields, err := data.LookupFields(strings.Split(RequiredFields+",unit_rate,date", ","))
+	if err != nil {
+		t.Fatalf("LookupFields: %v", err)
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			var records bytes.Buffer
+			if err := output.Encode(&records, format, output.NewValuteTable(original.Valutes, fields)); err != nil {
+				t.Fatalf("writing %s: %v", format, err)
+			}
+
+			rebuilt, err := Read(&records, format, "")
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+
+			rebuilt.Name = original.Name
+
+			encoded, err := Marshal(rebuilt)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			assertFeedBytes(t, encoded)
+
+			rebuiltPath := filepath.Join(t.TempDir(), "rebuilt.xml")
+			if err := os.WriteFile(rebuiltPath, encoded, 0o600); err != nil {
+				t.Fatalf("writing the rebuilt feed: %v", err)
+			}
+
+			decoded, err := xmldecoder.DecodeFile(rebuiltPath, xmldecoder.DefaultOptions())
+			if err != nil {
+				t.Fatalf("decoding the rebuilt feed: %v", err)
+			}
+
+			assertSameValCurs(t, original, decoded)
+		})
+	}
+}
+
+func TestReadReportsMissingFields(t *testing.T) {
+	t.Parallel()
+
+	_, err := Read(strings.NewReader(`[{"char_code": "USD", "num_code": 840, "value": 90.2834}]`), "json", "17.10.2026")
+	if err == nil {
+		t.Fatal("Read accepted a record without id, nominal and name")
+	}
+
+	if !strings.Contains(err.Error(), "id, nominal, name") {
+		t.Errorf("error %q does not list the missing fields", err)
+	}
+}
+
+func assertFeedBytes(t *testing.T, encoded []byte) {
+	t.Helper()
+
+	if !bytes.HasPrefix(encoded, []byte(`<?xml version="1.0" encoding="windows-1251"?>`)) {
+		t.Errorf("missing windows-1251 declaration: %.60q", encoded)
+	}
+
+	// "Доллар США" in windows-1251.
+	if !bytes.Contains(encoded, []byte("<Name>\xc4\xee\xeb\xeb\xe0\xf0 \xd1\xd8\xc0</Name>")) {
+		t.Errorf("USD name is not windows-1251 encoded: %q", encoded)
+	}
+
+	if !bytes.Contains(encoded, []byte("<Value>90,2834</Value>")) ||
+		!bytes.Contains(encoded, []byte("<VunitRate>0,605012</VunitRate>")) {
+		t.Errorf("numbers are not written with comma decimals: %q", encoded)
+	}
+}
+
+func assertSameValCurs(t *testing.T, want, got *data.ValCurs) {
+	t.Helper()
+
+	if got.Date != want.Date || got.Name != want.Name {
+		t.Errorf("ValCurs Date/name = %q/%q, want %q/%q", got.Date, got.Name, want.Date, want.Name)
+	}
+
+	if len(got.Valutes) != len(want.Valutes) {
+		t.Fatalf("got %d valutes, want %d", len(got.Valutes), len(want.Valutes))
+	}
+
+	byID := make(map[string]data.Valute, len(got.Valutes))
+	for _, valute := range got.Valutes {
+		byID[valute.ID] = valute
+	}
+
+	for _, expected := range want.Valutes {
+		actual, ok := byID[expected.ID]
+		if !ok {
+			t.Errorf("valute %s is missing", expected.ID)
+
+			continue
+		}
+
+		if actual.NumCode != expected.NumCode || actual.CharCode != expected.CharCode ||
+			actual.Nominal != expected.Nominal || actual.Name != expected.Name {
+			t.Errorf("valute %s = %d/%s/%d/%s, want %d/%s/%d/%s", expected.ID,
+				actual.NumCode, actual.CharCode, actual.Nominal, actual.Name,
+				expected.NumCode, expected.CharCode, expected.Nominal, expected.Name)
+		}
+
+		if actual.Value.Cmp(expected.Value) != 0 || actual.UnitRate.Cmp(expected.UnitRate) != 0 {
+			t.Errorf("valute %s Value/VunitRate = %v/%v, want %v/%v", expected.ID,
+				actual.Value, actual.UnitRate, expected.Value, expected.UnitRate)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cbrxml
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("reverse conversion supports json, ndjson and yaml only")
+	ErrMissingDate       = errors.New("no date in records; pass one explicitly")
+	ErrMixedDates        = errors.New("records carry different dates")
+	ErrMissingField      = errors.New("record is missing required fields")
+)
+
+// RequiredFields lists the output fields Read needs to rebuild a feed
+// document, in the order the service writes them. unit_rate is optional and
+// date may be given to Read instead.
+const RequiredFields = "id,num_code,char_code,nominal,name,value"
+
+// record mirrors the field names of the service's own output. Pointers tell
+// a missing field apart from a zero value.
+type record struct {
+	ID       *string             `json:"id"        yaml:"id"`
+	NumCode  *int                `json:"num_code"  yaml:"num_code"`
+	CharCode *string             `json:"char_code" yaml:"char_code"`
+	Nominal  *int                `json:"nominal"   yaml:"nominal"`
+	Name     *string             `json:"name"      yaml:"name"`
+	Value    *data.CurrencyValue `json:"value"     yaml:"value"`
+	UnitRate data.CurrencyValue  `json:"unit_rate" yaml:"unit_rate"`
+	Date     string              `json:"date"      yaml:"date"`
+}
+
+// Read rebuilds a ValCurs from output written in format. Every record must
+// carry the RequiredFields; the document date comes from the records' date
+// field unless date is non-empty.
+func Read(reader io.Reader, format, date string) (*data.ValCurs, error) {
+	records, err := decodeRecords(reader, format)
+	if err != nil {
+		return nil, err
+	}
+
+	valCurs := &data.ValCurs{Date: date, Name: MarketName, Valutes: make(data.CurrencyList, 0, len(records))}
+
+	for index, rec := range records {
+		valute, err := rec.toValute(index)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", index, err)
+		}
+
+		if date == "" && rec.Date != "" {
+			if valCurs.Date != "" && valCurs.Date != rec.Date {
+				return nil, fmt.Errorf("%w: %s and %s", ErrMixedDates, valCurs.Date, rec.Date)
+			}
+
+			valCurs.Date = rec.Date
+		}
+
+		valCurs.Valutes = append(valCurs.Valutes, valute)
+	}
+
+	if valCurs.Date == "" {
+		return nil, ErrMissingDate
+	}
+
+	return valCurs, nil
+}
+
+func decodeRecords(reader io.Reader, format string) ([]record, error) {
+	var records []record
+
+	switch format {
+	case "json":
+		if err := json.NewDecoder(reader).Decode(&records); err != nil {
+			return nil, fmt.Errorf("decoding JSON records: %w", err)
+		}
+	case "ndjson":
+		decoder := json.NewDecoder(reader)
+
+		for {
+			var rec record
+
+			err := decoder.Decode(&rec)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("decoding NDJSON record %d: %w", len(records), err)
+			}
+
+			records = append(records, rec)
+		}
+	case "yaml":
+		if err := yaml.NewDecoder(reader).Decode(&records); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("decoding YAML records: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w, got %q", ErrUnsupportedFormat, format)
+	}
+
+	return records, nil
+}
+
+func (r record) toValute(index int) (data.Valute, error) {
+	var missing []string
+
+	for _, field := range []struct {
+		name    string
+		present bool
+	}{
+		{name: "id", present: r.ID != nil},
+		{name: "num_code", present: r.NumCode != nil},
+		{name: "char_code", present: r.CharCode != nil && *r.CharCode != ""},
+		{name: "nominal", present: r.Nominal != nil},
+		{name: "name", present: r.Name != nil},
+		{name: "value", present: r.Value != nil},
+	} {
+		if !field.present {
+			missing = append(missing, field.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return data.Valute{}, fmt.Errorf("%w: %s (export with -fields %s,unit_rate,date)",
+			ErrMissingField, strings.Join(missing, ", "), RequiredFields)
+	}
+
+	return data.Valute{
+		ID:       *r.ID,
+		Nominal:  *r.Nominal,
+		Name:     *r.Name,
+		CharCode: *r.CharCode,
+		NumCode:  *r.NumCode,
+		Value:    *r.Value,
+		UnitRate: r.UnitRate,
+		Source:   "",
+		Index:    index,
+		Date:     r.Date,
+		Info:     data.CurrencyInfo{},
+	}, nil
+}
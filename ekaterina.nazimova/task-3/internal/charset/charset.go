@@ -0,0 +1,117 @@
+package charset
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const bomPeekSize = 3
+
+var ErrUnsupportedCharset = errors.New("unsupported charset")
+
+func aliases() map[string]string {
+	return map[string]string{
+		"win1251":     "windows-1251",
+		"win-1251":    "windows-1251",
+		"windows1251": "windows-1251",
+		"cp-1251":     "windows-1251",
+		"koi8r":       "koi8-r",
+		"utf16":       "utf-16",
+		"utf8":        "utf-8",
+	}
+}
+
+func Lookup(name string) (encoding.Encoding, error) {
+	label := strings.ToLower(strings.TrimSpace(name))
+	if canonical, ok := aliases()[label]; ok {
+		label = canonical
+	}
+
+	if label == "utf-16" {
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	}
+
+	if enc, err := htmlindex.Get(label); err == nil {
+		return enc, nil
+	}
+
+	if enc, err := ianaindex.IANA.Encoding(label); err == nil && enc != nil {
+		return enc, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCharset, name)
+}
+
+type boms struct {
+	mark     []byte
+	encoding encoding.Encoding
+}
+
+func knownBOMs() []boms {
+	return []boms{
+		{mark: []byte{0xEF, 0xBB, 0xBF}, encoding: unicode.UTF8BOM},
+		{mark: []byte{0xFF, 0xFE}, encoding: unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)},
+		{mark: []byte{0xFE, 0xFF}, encoding: unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)},
+	}
+}
+
+// Reader wraps an XML input and reports whether its encoding has already
+// been settled by a byte order mark or an explicit override. In that case the
+// text is already UTF-8 and the charset named in the XML declaration must be
+// ignored.
+type Reader struct {
+	io.Reader
+
+	Decided bool
+}
+
+func NewReader(input io.Reader, override string) (*Reader, error) {
+	buffered := bufio.NewReader(input)
+
+	head, err := buffered.Peek(bomPeekSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("sniffing byte order mark: %w", err)
+	}
+
+	for _, bom := range knownBOMs() {
+		if bytes.HasPrefix(head, bom.mark) {
+			return &Reader{Reader: bom.encoding.NewDecoder().Reader(buffered), Decided: true}, nil
+		}
+	}
+
+	if override == "" {
+		return &Reader{Reader: buffered, Decided: false}, nil
+	}
+
+	enc, err := Lookup(override)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{Reader: enc.NewDecoder().Reader(buffered), Decided: true}, nil
+}
+
+// XMLCharsetReader returns a function suitable for xml.Decoder.CharsetReader.
+func (r *Reader) XMLCharsetReader() func(label string, input io.Reader) (io.Reader, error) {
+	return func(label string, input io.Reader) (io.Reader, error) {
+		if r.Decided {
+			return input, nil
+		}
+
+		enc, err := Lookup(label)
+		if err != nil {
+			return nil, err
+		}
+
+		return enc.NewDecoder().Reader(input), nil
+	}
+}
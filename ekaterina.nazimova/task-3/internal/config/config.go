@@ -1,43 +1,458 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/UwUshkin/task-3/internal/charset"
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/filter"
+	"github.com/UwUshkin/task-3/internal/iso4217"
+	"github.com/UwUshkin/task-3/internal/numlocale"
+	"github.com/UwUshkin/task-3/internal/output"
+	"github.com/UwUshkin/task-3/internal/sorter"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
 	"gopkg.in/yaml.v3"
 )
 
-const dirPermissions = 0o755
+const (
+	EnvPrefix        = "TASK3_"
+	DefaultSortKey   = "unit_rate"
+	DefaultSortOrder = sorter.OrderDesc
+	PrecisionAsIs    = -1
+	maxPrecision     = 8
+	maxBackups       = 100
+	yamlIndent       = 2
+
+	ExpressionSeparator = ";"
+)
+
+var (
+	ErrMissingKey   = errors.New("missing required config key")
+	ErrInvalidValue = errors.New("invalid config value")
+)
+
+type Filters struct {
+	CharCodes   []string            `yaml:"char-codes,omitempty"`
+	MinValue    *data.CurrencyValue `yaml:"min-value,omitempty"`
+	MaxValue    *data.CurrencyValue `yaml:"max-value,omitempty"`
+	Expressions []string            `yaml:"expressions,omitempty"`
+}
+
+// Output is one extra file produced from the shared decoded data. An empty
+// Format is taken from the file extension; empty Fields and Sort fall back to
+// the top-level fields and sort.
+type Output struct {
+	File   string   `yaml:"file"`
+	Format string   `yaml:"format,omitempty"`
+	Fields []string `yaml:"fields,omitempty"`
+	Sort   string   `yaml:"sort,omitempty"`
+}
 
 type Config struct {
-	InputFile  string `yaml:"input-file"`
-	OutputFile string `yaml:"output-file"`
+	InputFile       string   `yaml:"input-file"`
+	OutputFile      string   `yaml:"output-file"`
+	OutputFormat    string   `yaml:"output-format"`
+	OutputBackups   int      `yaml:"output-backups"`
+	SortKey         string   `yaml:"sort-key"`
+	SortOrder       string   `yaml:"sort-order"`
+	Sort            string   `yaml:"sort"`
+	Precision       int      `yaml:"precision"`
+	Rounding        string   `yaml:"rounding"`
+	InputLocale     string   `yaml:"input-locale"`
+	InputEncoding   string   `yaml:"input-encoding"`
+	DecodeMode      string   `yaml:"decode-mode"`
+	OutputLocale    string   `yaml:"output-locale"`
+	CurrencyCatalog string   `yaml:"currency-catalog"`
+	Fields          []string `yaml:"fields"`
+	Filters         Filters  `yaml:"filters"`
+	Outputs         []Output `yaml:"outputs,omitempty"`
+
+	// Catalog is the currency table loaded from CurrencyCatalog by
+	// LoadCatalog; catalogPath records which file it came from.
+	Catalog     *iso4217.Table `yaml:"-"`
+	catalogPath string
+}
+
+func Default() *Config {
+	return &Config{
+		InputFile:       "",
+		OutputFile:      "",
+		OutputFormat:    "",
+		OutputBackups:   0,
+		SortKey:         DefaultSortKey,
+		SortOrder:       DefaultSortOrder,
+		Sort:            "",
+		Precision:       PrecisionAsIs,
+		Rounding:        data.RoundHalfUp.String(),
+		InputLocale:     numlocale.Russian().Name,
+		InputEncoding:   "",
+		DecodeMode:      xmldecoder.ModeStrict.String(),
+		OutputLocale:    "",
+		CurrencyCatalog: "",
+		Fields:          data.DefaultFieldNames(),
+		Filters: Filters{
+			CharCodes:   nil,
+			MinValue:    nil,
+			MaxValue:    nil,
+			Expressions: nil,
+		},
+		Outputs:     nil,
+		Catalog:     nil,
+		catalogPath: "",
+	}
 }
 
 func LoadConfig(path string) (*Config, error) {
-	fileData, err := os.ReadFile(path)
+	configFile, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading config file %q: %w", path, err)
 	}
+	defer configFile.Close()
+
+	cfg := Default()
+
+	decoder := yaml.NewDecoder(configFile)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unmarshalling config data: %w", err)
+	}
+
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) Validate() error {
+	if strings.TrimSpace(c.InputFile) == "" {
+		return fmt.Errorf("%w: input-file", ErrMissingKey)
+	}
+
+	if c.OutputFile != "" {
+		format, err := output.Resolve(c.OutputFormat, c.OutputFile)
+		if err != nil {
+			return fmt.Errorf("%w: output-format: %w", ErrInvalidValue, err)
+		}
+
+		c.OutputFormat = format
+	}
+
+	if c.OutputBackups < 0 || c.OutputBackups > maxBackups {
+		return fmt.Errorf("%w: output-backups: %d is outside [0, %d]", ErrInvalidValue, c.OutputBackups, maxBackups)
+	}
+
+	if err := c.validateSort(); err != nil {
+		return err
+	}
+
+	if c.Precision < PrecisionAsIs || c.Precision > maxPrecision {
+		return fmt.Errorf("%w: precision: %d is outside [%d, %d]",
+			ErrInvalidValue, c.Precision, PrecisionAsIs, maxPrecision)
+	}
+
+	if _, err := data.ParseRoundingMode(c.Rounding); err != nil {
+		return fmt.Errorf("%w: rounding: %w", ErrInvalidValue, err)
+	}
+
+	if _, err := numlocale.Lookup(c.InputLocale); err != nil {
+		return fmt.Errorf("%w: input-locale: %w", ErrInvalidValue, err)
+	}
+
+	if c.InputEncoding != "" {
+		if _, err := charset.Lookup(c.InputEncoding); err != nil {
+			return fmt.Errorf("%w: input-encoding: %w", ErrInvalidValue, err)
+		}
+	}
+
+	if _, err := xmldecoder.ParseMode(c.DecodeMode); err != nil {
+		return fmt.Errorf("%w: decode-mode: %w", ErrInvalidValue, err)
+	}
+
+	if c.OutputLocale != "" {
+		if _, err := numlocale.Lookup(c.OutputLocale); err != nil {
+			return fmt.Errorf("%w: output-locale: %w", ErrInvalidValue, err)
+		}
+	}
+
+	if _, err := c.LoadCatalog(); err != nil {
+		return fmt.Errorf("%w: currency-catalog: %w", ErrInvalidValue, err)
+	}
+
+	if len(c.Fields) == 0 {
+		return fmt.Errorf("%w: fields", ErrMissingKey)
+	}
+
+	if _, err := data.LookupFields(c.Fields); err != nil {
+		return fmt.Errorf("%w: fields: %w", ErrInvalidValue, err)
+	}
 
-	cfg := &Config{
-		InputFile:  "",
-		OutputFile: "",
+	if err := c.Filters.validate(); err != nil {
+		return err
 	}
 
-	err = yaml.Unmarshal(fileData, cfg)
+	return c.validateOutputs()
+}
+
+// LoadCatalog returns Catalog, loading it first unless it was already loaded
+// from the current CurrencyCatalog. Copies of a config share the table.
+func (c *Config) LoadCatalog() (*iso4217.Table, error) {
+	if c.Catalog != nil && c.catalogPath == c.CurrencyCatalog {
+		return c.Catalog, nil
+	}
+
+	catalog, err := iso4217.Load(c.CurrencyCatalog)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshalling config data: %w", err)
+		return nil, err
 	}
 
-	outputDirectory := filepath.Dir(cfg.OutputFile)
-	if _, err := os.Stat(outputDirectory); os.IsNotExist(err) {
-		err := os.MkdirAll(outputDirectory, dirPermissions)
+	c.Catalog, c.catalogPath = catalog, c.CurrencyCatalog
+
+	return catalog, nil
+}
+
+// RequireOutput reports whether the config names at least one file to write.
+// Validate leaves this out so read-only commands only need input-file.
+func (c *Config) RequireOutput() error {
+	if strings.TrimSpace(c.OutputFile) == "" && len(c.Outputs) == 0 {
+		return fmt.Errorf("%w: output-file or outputs", ErrMissingKey)
+	}
+
+	return nil
+}
+
+func (c *Config) validateOutputs() error {
+	for idx := range c.Outputs {
+		out := &c.Outputs[idx]
+		key := fmt.Sprintf("outputs[%d]", idx)
+
+		if strings.TrimSpace(out.File) == "" {
+			return fmt.Errorf("%w: %s.file", ErrMissingKey, key)
+		}
+
+		resolved, err := output.Resolve(out.Format, out.File)
 		if err != nil {
-			return nil, fmt.Errorf("creating output directory %q: %w", outputDirectory, err)
+			return fmt.Errorf("%w: %s.format: %w", ErrInvalidValue, key, err)
+		}
+
+		out.Format = resolved
+
+		if _, err := data.LookupFields(out.Fields); err != nil {
+			return fmt.Errorf("%w: %s.fields: %w", ErrInvalidValue, key, err)
+		}
+
+		if out.Sort != "" {
+			spec, err := sorter.ParseSpec(out.Sort)
+			if err != nil {
+				return fmt.Errorf("%w: %s.sort: %w", ErrInvalidValue, key, err)
+			}
+
+			out.Sort = spec.String()
 		}
 	}
 
-	return cfg, nil
+	return nil
+}
+
+// ResolvedOutputs lists every file to write: output-file first, when set,
+// followed by the outputs list, with fields and sort filled from the
+// top-level settings. Call it on a validated config.
+func (c *Config) ResolvedOutputs() []Output {
+	resolved := make([]Output, 0, len(c.Outputs)+1)
+
+	if c.OutputFile != "" {
+		resolved = append(resolved, Output{File: c.OutputFile, Format: c.OutputFormat, Fields: c.Fields, Sort: c.Sort})
+	}
+
+	for _, out := range c.Outputs {
+		if len(out.Fields) == 0 {
+			out.Fields = c.Fields
+		}
+
+		if out.Sort == "" {
+			out.Sort = c.Sort
+		}
+
+		resolved = append(resolved, out)
+	}
+
+	return resolved
+}
+
+func (c *Config) validateSort() error {
+	if c.Sort == "" {
+		if _, err := data.LookupField(c.SortKey); err != nil {
+			return fmt.Errorf("%w: sort-key: %w", ErrInvalidValue, err)
+		}
+
+		if _, err := sorter.ParseOrder(c.SortOrder); err != nil {
+			return fmt.Errorf("%w: sort-order: %w", ErrInvalidValue, err)
+		}
+
+		c.Sort = c.SortKey + ":" + c.SortOrder
+	}
+
+	spec, err := sorter.ParseSpec(c.Sort)
+	if err != nil {
+		return fmt.Errorf("%w: sort: %w", ErrInvalidValue, err)
+	}
+
+	c.Sort = spec.String()
+
+	return nil
+}
+
+func (f *Filters) validate() error {
+	for _, charCode := range f.CharCodes {
+		if strings.TrimSpace(charCode) == "" {
+			return fmt.Errorf("%w: filters.char-codes: empty char code", ErrInvalidValue)
+		}
+	}
+
+	if _, err := filter.ParseExpressions(f.Expressions); err != nil {
+		return fmt.Errorf("%w: filters.expressions: %w", ErrInvalidValue, err)
+	}
+
+	if f.MinValue != nil && f.MaxValue != nil && f.MinValue.Cmp(*f.MaxValue) > 0 {
+		return fmt.Errorf("%w: filters.min-value: %v is greater than filters.max-value %v",
+			ErrInvalidValue, *f.MinValue, *f.MaxValue)
+	}
+
+	return nil
+}
+
+type envBinding struct {
+	key   string
+	apply func(cfg *Config, value string) error
+}
+
+func envBindings() []envBinding {
+	return []envBinding{
+		{key: "input-file", apply: setString(func(cfg *Config) *string { return &cfg.InputFile })},
+		{key: "output-file", apply: setString(func(cfg *Config) *string { return &cfg.OutputFile })},
+		{key: "output-format", apply: setString(func(cfg *Config) *string { return &cfg.OutputFormat })},
+		{key: "output-backups", apply: func(cfg *Config, value string) error { return parseInt(value, &cfg.OutputBackups) }},
+		{key: "sort-key", apply: setString(func(cfg *Config) *string { return &cfg.SortKey })},
+		{key: "sort-order", apply: setString(func(cfg *Config) *string { return &cfg.SortOrder })},
+		{key: "sort", apply: setString(func(cfg *Config) *string { return &cfg.Sort })},
+		{key: "precision", apply: func(cfg *Config, value string) error { return parseInt(value, &cfg.Precision) }},
+		{key: "rounding", apply: setString(func(cfg *Config) *string { return &cfg.Rounding })},
+		{key: "input-locale", apply: setString(func(cfg *Config) *string { return &cfg.InputLocale })},
+		{key: "input-encoding", apply: setString(func(cfg *Config) *string { return &cfg.InputEncoding })},
+		{key: "decode-mode", apply: setString(func(cfg *Config) *string { return &cfg.DecodeMode })},
+		{key: "output-locale", apply: setString(func(cfg *Config) *string { return &cfg.OutputLocale })},
+		{key: "currency-catalog", apply: setString(func(cfg *Config) *string { return &cfg.CurrencyCatalog })},
+		{key: "fields", apply: setList(func(cfg *Config) *[]string { return &cfg.Fields }, ",")},
+		{key: "filters.char-codes", apply: setList(func(cfg *Config) *[]string { return &cfg.Filters.CharCodes }, ",")},
+		{key: "filters.expressions", apply: setList(func(cfg *Config) *[]string {
+			return &cfg.Filters.Expressions
+		}, ExpressionSeparator)},
+		{key: "filters.min-value", apply: func(cfg *Config, value string) error {
+			return parseOptionalDecimal(value, &cfg.Filters.MinValue)
+		}},
+		{key: "filters.max-value", apply: func(cfg *Config, value string) error {
+			return parseOptionalDecimal(value, &cfg.Filters.MaxValue)
+		}},
+	}
+}
+
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+func (c *Config) ApplyEnv(lookup func(name string) (string, bool)) error {
+	for _, binding := range envBindings() {
+		value, ok := lookup(EnvName(binding.key))
+		if !ok {
+			continue
+		}
+
+		if err := binding.apply(c, value); err != nil {
+			return fmt.Errorf("%w: %s (from %s): %w", ErrInvalidValue, binding.key, EnvName(binding.key), err)
+		}
+	}
+
+	return nil
+}
+
+func setString(target func(cfg *Config) *string) func(cfg *Config, value string) error {
+	return func(cfg *Config, value string) error {
+		*target(cfg) = value
+
+		return nil
+	}
+}
+
+func setList(target func(cfg *Config) *[]string, separator string) func(cfg *Config, value string) error {
+	return func(cfg *Config, value string) error {
+		*target(cfg) = SplitList(value, separator)
+
+		return nil
+	}
+}
+
+func parseInt(value string, target *int) error {
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("parsing integer: %w", err)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+func parseOptionalDecimal(value string, target **data.CurrencyValue) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		*target = nil
+
+		return nil
+	}
+
+	parsed, err := data.ParseCurrencyValue(value)
+	if err != nil {
+		return fmt.Errorf("parsing number: %w", err)
+	}
+
+	*target = &parsed
+
+	return nil
+}
+
+func SplitList(value, separator string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, separator) {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+
+	return items
+}
+
+func (c *Config) Dump(writer io.Writer) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(yamlIndent)
+
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("flushing config: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,286 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/numlocale"
+	"gopkg.in/yaml.v3"
+)
+
+type RoundingMode int
+
+const (
+	RoundHalfUp RoundingMode = iota
+	RoundHalfEven
+	RoundDown
+)
+
+const decimalBase = 10
+
+var (
+	ErrInvalidNumber       = errors.New("invalid decimal number")
+	ErrDivisionByZero      = errors.New("division by zero")
+	ErrInvalidRoundingMode = errors.New("invalid rounding mode")
+)
+
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "half-up":
+		return RoundHalfUp, nil
+	case "half-even":
+		return RoundHalfEven, nil
+	case "down":
+		return RoundDown, nil
+	default:
+		return RoundHalfUp, fmt.Errorf("%w %q (expected half-up, half-even or down)", ErrInvalidRoundingMode, name)
+	}
+}
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfEven:
+		return "half-even"
+	case RoundDown:
+		return "down"
+	default:
+		return "half-up"
+	}
+}
+
+type CurrencyValue struct {
+	unscaled *big.Int
+	scale    int
+}
+
+func NewCurrencyValue(unscaled int64, scale int) CurrencyValue {
+	return CurrencyValue{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+func CurrencyValueFromInt(value int) CurrencyValue {
+	return NewCurrencyValue(int64(value), 0)
+}
+
+func ParseCurrencyValue(text string) (CurrencyValue, error) {
+	trimmed := strings.TrimSpace(text)
+
+	sign := ""
+	if trimmed != "" && (trimmed[0] == '-' || trimmed[0] == '+') {
+		sign, trimmed = trimmed[:1], trimmed[1:]
+	}
+
+	integerPart, fractionPart, _ := strings.Cut(strings.Replace(trimmed, ",", ".", 1), ".")
+	if integerPart == "" && fractionPart == "" || !isDigits(integerPart) || !isDigits(fractionPart) {
+		return CurrencyValue{}, fmt.Errorf("%w: %q", ErrInvalidNumber, text)
+	}
+
+	unscaled, ok := new(big.Int).SetString(sign+integerPart+fractionPart, decimalBase)
+	if !ok {
+		return CurrencyValue{}, fmt.Errorf("%w: %q", ErrInvalidNumber, text)
+	}
+
+	return CurrencyValue{unscaled: unscaled, scale: len(fractionPart)}, nil
+}
+
+func ParseLocalizedCurrencyValue(text string, locale numlocale.Locale) (CurrencyValue, error) {
+	canonical, err := locale.Normalize(text)
+	if err != nil {
+		return CurrencyValue{}, fmt.Errorf("%w: %w", ErrInvalidNumber, err)
+	}
+
+	return ParseCurrencyValue(canonical)
+}
+
+func isDigits(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c CurrencyValue) bigInt() *big.Int {
+	if c.unscaled == nil {
+		return new(big.Int)
+	}
+
+	return c.unscaled
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(decimalBase), big.NewInt(int64(exponent)), nil)
+}
+
+func (c CurrencyValue) rescaled(scale int) *big.Int {
+	if scale <= c.scale {
+		return new(big.Int).Set(c.bigInt())
+	}
+
+	return new(big.Int).Mul(c.bigInt(), pow10(scale-c.scale))
+}
+
+func (c CurrencyValue) Scale() int {
+	return c.scale
+}
+
+func (c CurrencyValue) Sign() int {
+	return c.bigInt().Sign()
+}
+
+func (c CurrencyValue) IsZero() bool {
+	return c.Sign() == 0
+}
+
+func (c CurrencyValue) Cmp(other CurrencyValue) int {
+	scale := max(c.scale, other.scale)
+
+	return c.rescaled(scale).Cmp(other.rescaled(scale))
+}
+
+func (c CurrencyValue) Add(other CurrencyValue) CurrencyValue {
+	scale := max(c.scale, other.scale)
+
+	return CurrencyValue{unscaled: new(big.Int).Add(c.rescaled(scale), other.rescaled(scale)), scale: scale}
+}
+
+func (c CurrencyValue) Sub(other CurrencyValue) CurrencyValue {
+	return c.Add(other.Neg())
+}
+
+func (c CurrencyValue) Neg() CurrencyValue {
+	return CurrencyValue{unscaled: new(big.Int).Neg(c.bigInt()), scale: c.scale}
+}
+
+func (c CurrencyValue) Abs() CurrencyValue {
+	return CurrencyValue{unscaled: new(big.Int).Abs(c.bigInt()), scale: c.scale}
+}
+
+func (c CurrencyValue) Mul(other CurrencyValue) CurrencyValue {
+	return CurrencyValue{unscaled: new(big.Int).Mul(c.bigInt(), other.bigInt()), scale: c.scale + other.scale}
+}
+
+// Quo divides by divisor and rounds the result to scale decimal places; a
+// negative scale is treated as zero, as in Round.
+func (c CurrencyValue) Quo(divisor CurrencyValue, scale int, mode RoundingMode) (CurrencyValue, error) {
+	if divisor.IsZero() {
+		return CurrencyValue{}, ErrDivisionByZero
+	}
+
+	scale = max(scale, 0)
+
+	numerator := new(big.Int).Set(c.bigInt())
+	denominator := new(big.Int).Set(divisor.bigInt())
+
+	if shift := scale + divisor.scale - c.scale; shift >= 0 {
+		numerator.Mul(numerator, pow10(shift))
+	} else {
+		denominator.Mul(denominator, pow10(-shift))
+	}
+
+	return CurrencyValue{unscaled: divideRounded(numerator, denominator, mode), scale: scale}, nil
+}
+
+func divideRounded(numerator, denominator *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 || mode == RoundDown {
+		return quotient
+	}
+
+	doubled := new(big.Int).Abs(remainder)
+	doubled.Lsh(doubled, 1)
+
+	half := doubled.Cmp(new(big.Int).Abs(denominator))
+	if half > 0 || half == 0 && (mode == RoundHalfUp || quotient.Bit(0) == 1) {
+		quotient.Add(quotient, big.NewInt(int64(remainder.Sign()*denominator.Sign())))
+	}
+
+	return quotient
+}
+
+// Round rounds to places decimal places. A negative places is treated as
+// zero, since a CurrencyValue never carries a negative scale.
+func (c CurrencyValue) Round(places int, mode RoundingMode) CurrencyValue {
+	places = max(places, 0)
+
+	if places >= c.scale {
+		return CurrencyValue{unscaled: c.rescaled(places), scale: places}
+	}
+
+	return CurrencyValue{unscaled: divideRounded(c.bigInt(), pow10(c.scale-places), mode), scale: places}
+}
+
+func (c CurrencyValue) Reduce() CurrencyValue {
+	unscaled, scale := new(big.Int).Set(c.bigInt()), c.scale
+	remainder, ten := new(big.Int), big.NewInt(decimalBase)
+
+	for scale > 0 {
+		quotient, rem := new(big.Int).QuoRem(unscaled, ten, remainder)
+		if rem.Sign() != 0 {
+			break
+		}
+
+		unscaled, scale = quotient, scale-1
+	}
+
+	return CurrencyValue{unscaled: unscaled, scale: scale}
+}
+
+func (c CurrencyValue) String() string {
+	digits := new(big.Int).Abs(c.bigInt()).String()
+
+	sign := ""
+	if c.Sign() < 0 {
+		sign = "-"
+	}
+
+	if c.scale <= 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= c.scale {
+		digits = strings.Repeat("0", c.scale-len(digits)+1) + digits
+	}
+
+	point := len(digits) - c.scale
+
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (c CurrencyValue) Format(locale numlocale.Locale) string {
+	return locale.Format(c.String())
+}
+
+func (c CurrencyValue) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *CurrencyValue) UnmarshalText(text []byte) error {
+	parsed, err := ParseCurrencyValue(string(text))
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+
+	return nil
+}
+
+func (c CurrencyValue) MarshalJSON() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *CurrencyValue) UnmarshalJSON(raw []byte) error {
+	return c.UnmarshalText([]byte(strings.Trim(string(raw), `"`)))
+}
+
+func (c CurrencyValue) MarshalYAML() (any, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: c.String()}, nil
+}
+
+func (c *CurrencyValue) UnmarshalYAML(node *yaml.Node) error {
+	return c.UnmarshalText([]byte(node.Value))
+}
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func mustParse(t *testing.T, text string) CurrencyValue {
+	t.Helper()
+
+	value, err := ParseCurrencyValue(text)
+	if err != nil {
+		t.Fatalf("ParseCurrencyValue(%q): %v", text, err)
+	}
+
+	return value
+}
+
+func TestParseCurrencyValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text  string
+		want  string
+		scale int
+		err   bool
+	}{
+		{text: "90,2834", want: "90.2834", scale: 4},
+		{text: "90.2834", want: "90.2834", scale: 4},
+		{text: " -0,605012 ", want: "-0.605012", scale: 6},
+		{text: "+12", want: "12", scale: 0},
+		{text: "0.00361234", want: "0.00361234", scale: 8},
+		{text: ".5", want: "0.5", scale: 1},
+		{text: "5.", want: "5", scale: 0},
+		{text: "123456789012345678901234567890.123456789", want: "123456789012345678901234567890.123456789", scale: 9},
+		{text: "", err: true},
+		{text: "-", err: true},
+		{text: "1.2.3", err: true},
+		{text: "12a", err: true},
+		{text: "1 000", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseCurrencyValue(test.text)
+		if test.err {
+			if !errors.Is(err, ErrInvalidNumber) {
+				t.Errorf("ParseCurrencyValue(%q) error = %v, want ErrInvalidNumber", test.text, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseCurrencyValue(%q): %v", test.text, err)
+
+			continue
+		}
+
+		if got.String() != test.want || got.Scale() != test.scale {
+			t.Errorf("ParseCurrencyValue(%q) = %s (scale %d), want %s (scale %d)",
+				test.text, got, got.Scale(), test.want, test.scale)
+		}
+	}
+}
+
+func TestQuo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dividend string
+		divisor  string
+		scale    int
+		mode     RoundingMode
+		want     string
+	}{
+		{dividend: "60.5012", divisor: "100", scale: 6, mode: RoundHalfUp, want: "0.605012"},
+		{dividend: "1", divisor: "3", scale: 4, mode: RoundHalfUp, want: "0.3333"},
+		{dividend: "2", divisor: "3", scale: 4, mode: RoundHalfUp, want: "0.6667"},
+		{dividend: "2", divisor: "3", scale: 4, mode: RoundDown, want: "0.6666"},
+		{dividend: "1", divisor: "8", scale: 2, mode: RoundHalfUp, want: "0.13"},
+		{dividend: "1", divisor: "8", scale: 2, mode: RoundHalfEven, want: "0.12"},
+		{dividend: "3", divisor: "8", scale: 2, mode: RoundHalfEven, want: "0.38"},
+		{dividend: "-1", divisor: "8", scale: 2, mode: RoundHalfUp, want: "-0.13"},
+		{dividend: "-1", divisor: "8", scale: 2, mode: RoundHalfEven, want: "-0.12"},
+		{dividend: "-2", divisor: "3", scale: 4, mode: RoundDown, want: "-0.6666"},
+		{dividend: "1", divisor: "-8", scale: 2, mode: RoundHalfUp, want: "-0.13"},
+		{dividend: "90.2834", divisor: "0.605012", scale: 4, mode: RoundHalfUp, want: "149.2258"},
+		{dividend: "25", divisor: "10", scale: -1, mode: RoundHalfEven, want: "2"},
+	}
+
+	for _, test := range tests {
+		got, err := mustParse(t, test.dividend).Quo(mustParse(t, test.divisor), test.scale, test.mode)
+		if err != nil {
+			t.Errorf("%s / %s: %v", test.dividend, test.divisor, err)
+
+			continue
+		}
+
+		if got.String() != test.want {
+			t.Errorf("%s / %s (scale %d, %s) = %s, want %s",
+				test.dividend, test.divisor, test.scale, test.mode, got, test.want)
+		}
+	}
+
+	if _, err := mustParse(t, "1").Quo(CurrencyValue{}, 2, RoundHalfUp); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("division by zero error = %v, want ErrDivisionByZero", err)
+	}
+}
+
+func TestRound(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value  string
+		places int
+		mode   RoundingMode
+		want   string
+	}{
+		{value: "2.345", places: 2, mode: RoundHalfUp, want: "2.35"},
+		{value: "2.345", places: 2, mode: RoundHalfEven, want: "2.34"},
+		{value: "2.355", places: 2, mode: RoundHalfEven, want: "2.36"},
+		{value: "2.349", places: 2, mode: RoundDown, want: "2.34"},
+		{value: "-2.345", places: 2, mode: RoundHalfUp, want: "-2.35"},
+		{value: "-2.345", places: 2, mode: RoundHalfEven, want: "-2.34"},
+		{value: "-2.349", places: 2, mode: RoundDown, want: "-2.34"},
+		{value: "0.5", places: 0, mode: RoundHalfEven, want: "0"},
+		{value: "1.5", places: 0, mode: RoundHalfEven, want: "2"},
+		{value: "90.2834", places: 6, mode: RoundHalfUp, want: "90.283400"},
+		{value: "100", places: -1, mode: RoundHalfUp, want: "100"},
+		{value: "104.6", places: -2, mode: RoundHalfUp, want: "105"},
+	}
+
+	for _, test := range tests {
+		got := mustParse(t, test.value).Round(test.places, test.mode)
+		if got.String() != test.want {
+			t.Errorf("Round(%s, %d, %s) = %s, want %s", test.value, test.places, test.mode, got, test.want)
+		}
+	}
+}
+
+func TestCurrencyValueRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{"90.2834", "-0.605012", "0", "0.00361234", "115.50"} {
+		value := mustParse(t, text)
+
+		encodedJSON, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("json.Marshal(%s): %v", text, err)
+		}
+
+		var fromJSON CurrencyValue
+		if err := json.Unmarshal(encodedJSON, &fromJSON); err != nil || fromJSON.String() != text {
+			t.Errorf("JSON round trip of %s = %s (%v), encoded as %s", text, fromJSON, err, encodedJSON)
+		}
+
+		var quoted CurrencyValue
+		if err := json.Unmarshal([]byte(`"`+text+`"`), &quoted); err != nil || quoted.String() != text {
+			t.Errorf("quoted JSON %q decoded as %s (%v)", text, quoted, err)
+		}
+
+		encodedYAML, err := yaml.Marshal(value)
+		if err != nil {
+			t.Fatalf("yaml.Marshal(%s): %v", text, err)
+		}
+
+		var fromYAML CurrencyValue
+		if err := yaml.Unmarshal(encodedYAML, &fromYAML); err != nil || fromYAML.String() != text {
+			t.Errorf("YAML round trip of %s = %s (%v), encoded as %s", text, fromYAML, err, encodedYAML)
+		}
+
+		encodedText, err := value.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%s): %v", text, err)
+		}
+
+		var fromText CurrencyValue
+		if err := fromText.UnmarshalText(encodedText); err != nil || fromText.String() != text {
+			t.Errorf("text round trip of %s = %s (%v)", text, fromText, err)
+		}
+	}
+}
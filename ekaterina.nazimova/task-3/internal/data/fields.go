@@ -0,0 +1,108 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnknownField = errors.New("unknown Valute field")
+
+type Field struct {
+	Name    string
+	XMLName string
+	Value   func(valute Valute) any
+}
+
+func AllFields() []Field {
+	return []Field{
+		{Name: "id", XMLName: "ID", Value: func(v Valute) any { return v.ID }},
+		{Name: "num_code", XMLName: "NumCode", Value: func(v Valute) any { return v.NumCode }},
+		{Name: "char_code", XMLName: "CharCode", Value: func(v Valute) any { return v.CharCode }},
+		{Name: "nominal", XMLName: "Nominal", Value: func(v Valute) any { return v.Nominal }},
+		{Name: "name", XMLName: "Name", Value: func(v Valute) any { return v.Name }},
+		{Name: "value", XMLName: "Value", Value: func(v Valute) any { return v.Value }},
+		{Name: "unit_rate", XMLName: "VunitRate", Value: func(v Valute) any { return v.UnitRate }},
+		{Name: "date", XMLName: "Date", Value: func(v Valute) any { return v.Date }},
+		{Name: "source", XMLName: "Source", Value: func(v Valute) any { return v.Source }},
+		{Name: "iso_status", XMLName: "ISOStatus", Value: func(v Valute) any { return v.Info.Status }},
+		{Name: "english_name", XMLName: "EnglishName", Value: func(v Valute) any { return v.Info.EnglishName }},
+		{Name: "minor_units", XMLName: "MinorUnits", Value: func(v Valute) any { return v.Info.MinorUnits }},
+		{Name: "symbol", XMLName: "Symbol", Value: func(v Valute) any { return v.Info.Symbol }},
+		{Name: "countries", XMLName: "Countries", Value: func(v Valute) any {
+			return strings.Join(v.Info.Countries, " ")
+		}},
+	}
+}
+
+func DefaultFieldNames() []string {
+	return []string{"char_code", "num_code", "value", "unit_rate"}
+}
+
+func FieldNames() []string {
+	fields := AllFields()
+
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+func LookupField(name string) (Field, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+
+	for _, field := range AllFields() {
+		if field.Name == normalized {
+			return field, nil
+		}
+	}
+
+	return Field{}, fmt.Errorf("%w %q (available: %s)", ErrUnknownField, name, strings.Join(FieldNames(), ", "))
+}
+
+func LookupFields(names []string) ([]Field, error) {
+	fields := make([]Field, 0, len(names))
+
+	for _, name := range names {
+		field, err := LookupField(name)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func CompareValues(left, right any) int {
+	switch typedLeft := left.(type) {
+	case CurrencyValue:
+		typedRight, _ := right.(CurrencyValue)
+
+		return typedLeft.Cmp(typedRight)
+	case int:
+		typedRight, _ := right.(int)
+
+		return compareOrdered(typedLeft, typedRight)
+	case string:
+		typedRight, _ := right.(string)
+
+		return compareOrdered(typedLeft, typedRight)
+	default:
+		return compareOrdered(fmt.Sprint(left), fmt.Sprint(right))
+	}
+}
+
+func compareOrdered[T int | string](left, right T) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -1,41 +1,63 @@
 package data
 
 import (
-	"encoding/xml"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
-type CurrencyValue float64
+const DateLayout = "02.01.2006"
 
-func (c *CurrencyValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	var xmlString string
-	if err := d.DecodeElement(&xmlString, &start); err != nil {
-		return fmt.Errorf("decoding element: %w", err)
-	}
+type Valute struct {
+	ID      string `json:"-" xml:"ID,attr"`
+	Nominal int    `json:"-" xml:"Nominal"`
+	Name    string `json:"-" xml:"Name"`
 
-	cleanString := strings.Replace(xmlString, ",", ".", 1)
+	CharCode string `json:"char_code" xml:"CharCode"`
+	NumCode  int    `json:"num_code"  xml:"NumCode"`
 
-	parsedFloat, err := strconv.ParseFloat(cleanString, 64)
-	if err != nil {
-		return fmt.Errorf("parsing cleaned float %q: %w", cleanString, err)
-	}
+	Value    CurrencyValue `json:"value"     xml:"Value"`
+	UnitRate CurrencyValue `json:"unit_rate" xml:"VunitRate"`
 
-	*c = CurrencyValue(parsedFloat)
+	// Source, Index and Date locate the Valute element it was decoded from;
+	// Index counts every Valute of the document, skipped ones included.
+	Source string `json:"-" xml:"-"`
+	Index  int    `json:"-" xml:"-"`
+	Date   string `json:"-" xml:"-"`
 
-	return nil
+	Info CurrencyInfo `json:"-" xml:"-"`
 }
 
-type Valute struct {
-	ID         string `json:"-" xml:"ID,attr"`
-	NominalStr string `json:"-" xml:"Nominal"`
-	Name       string `json:"-" xml:"Name"`
+const (
+	ISOStatusKnown   = "known"
+	ISOStatusUnknown = "unknown"
+)
 
-	CharCode string `json:"char_code" xml:"CharCode"`
-	NumCode  int    `json:"num_code"  xml:"NumCode"`
+// CurrencyInfo is the ISO 4217 metadata attached by enrichment. Status stays
+// empty until the Valute has been enriched; MinorUnits is -1 when ISO 4217
+// defines none or the code is unknown.
+type CurrencyInfo struct {
+	Status      string
+	EnglishName string
+	MinorUnits  int
+	Symbol      string
+	Countries   []string
+}
+
+func (v *Valute) FillUnitRate() {
+	if !v.UnitRate.IsZero() || v.Nominal <= 0 {
+		return
+	}
+
+	unitRate, err := v.Value.Quo(CurrencyValueFromInt(v.Nominal), v.Value.Scale()+nominalDigits(v.Nominal), RoundHalfUp)
+	if err == nil {
+		v.UnitRate = unitRate.Reduce()
+	}
+}
 
-	Value CurrencyValue `json:"value" xml:"Value"`
+func nominalDigits(nominal int) int {
+	return len(strconv.Itoa(nominal))
 }
 
 type ValCurs struct {
@@ -44,6 +66,24 @@ type ValCurs struct {
 	Valutes CurrencyList `xml:"Valute"`
 }
 
+func (v *ValCurs) ParseDate() (time.Time, error) {
+	date, err := ParseDate(v.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing ValCurs Date attribute: %w", err)
+	}
+
+	return date, nil
+}
+
+func ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse(DateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing date %q: %w", value, err)
+	}
+
+	return date, nil
+}
+
 type CurrencyList []Valute
 
 func (c CurrencyList) Len() int {
@@ -55,5 +95,16 @@ func (c CurrencyList) Swap(i, j int) {
 }
 
 func (c CurrencyList) Less(i, j int) bool {
-	return c[i].Value > c[j].Value
+	if cmp := c[i].UnitRate.Cmp(c[j].UnitRate); cmp != 0 {
+		return cmp > 0
+	}
+
+	return c[i].CharCode < c[j].CharCode
+}
+
+func (c CurrencyList) Round(places int, mode RoundingMode) {
+	for idx := range c {
+		c[idx].Value = c[idx].Value.Round(places, mode)
+		c[idx].UnitRate = c[idx].UnitRate.Round(places, mode)
+	}
 }
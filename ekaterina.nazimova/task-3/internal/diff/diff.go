@@ -0,0 +1,177 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/output"
+)
+
+const (
+	StatusChanged = "changed"
+	StatusAdded   = "added"
+	StatusRemoved = "removed"
+
+	percentFactor = 100
+	percentScale  = 4
+)
+
+// Change compares the unit rate of one currency. Rates that do not apply,
+// such as OldRate of an added currency, are nil; PercentChange is also nil
+// when the old rate is zero.
+type Change struct {
+	CharCode      string
+	Status        string
+	OldRate       *data.CurrencyValue
+	NewRate       *data.CurrencyValue
+	Delta         *data.CurrencyValue
+	PercentChange *data.CurrencyValue
+}
+
+type Report struct {
+	OldDate string
+	NewDate string
+	Changes []Change
+}
+
+func Compare(previous, current *data.ValCurs) *Report {
+	oldRates := indexByCharCode(previous.Valutes)
+	newRates := indexByCharCode(current.Valutes)
+
+	report := &Report{
+		OldDate: previous.Date,
+		NewDate: current.Date,
+		Changes: make([]Change, 0, len(newRates)),
+	}
+
+	for charCode, newRate := range newRates {
+		oldRate, existed := oldRates[charCode]
+		if !existed {
+			report.Changes = append(report.Changes, Change{
+				CharCode:      charCode,
+				Status:        StatusAdded,
+				OldRate:       nil,
+				NewRate:       &newRate,
+				Delta:         nil,
+				PercentChange: nil,
+			})
+
+			continue
+		}
+
+		report.Changes = append(report.Changes, newChange(charCode, oldRate, newRate))
+	}
+
+	for charCode, oldRate := range oldRates {
+		if _, exists := newRates[charCode]; !exists {
+			report.Changes = append(report.Changes, Change{
+				CharCode:      charCode,
+				Status:        StatusRemoved,
+				OldRate:       &oldRate,
+				NewRate:       nil,
+				Delta:         nil,
+				PercentChange: nil,
+			})
+		}
+	}
+
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return lessByMagnitude(report.Changes[i], report.Changes[j])
+	})
+
+	return report
+}
+
+func newChange(charCode string, oldRate, newRate data.CurrencyValue) Change {
+	delta := newRate.Sub(oldRate)
+
+	change := Change{
+		CharCode:      charCode,
+		Status:        StatusChanged,
+		OldRate:       &oldRate,
+		NewRate:       &newRate,
+		Delta:         &delta,
+		PercentChange: nil,
+	}
+
+	percent, err := delta.Mul(data.CurrencyValueFromInt(percentFactor)).Quo(oldRate, percentScale, data.RoundHalfUp)
+	if err == nil {
+		change.PercentChange = &percent
+	}
+
+	return change
+}
+
+func lessByMagnitude(left, right Change) bool {
+	leftChanged, rightChanged := left.Status == StatusChanged, right.Status == StatusChanged
+	if leftChanged != rightChanged {
+		return leftChanged
+	}
+
+	if left.Status != right.Status {
+		return left.Status < right.Status
+	}
+
+	if cmp := magnitude(left.PercentChange).Cmp(magnitude(right.PercentChange)); cmp != 0 {
+		return cmp > 0
+	}
+
+	return left.CharCode < right.CharCode
+}
+
+func magnitude(percent *data.CurrencyValue) data.CurrencyValue {
+	if percent == nil {
+		return data.CurrencyValue{}
+	}
+
+	return percent.Abs()
+}
+
+func indexByCharCode(valutes data.CurrencyList) map[string]data.CurrencyValue {
+	index := make(map[string]data.CurrencyValue, len(valutes))
+	for _, valute := range valutes {
+		index[strings.ToUpper(valute.CharCode)] = valute.UnitRate
+	}
+
+	return index
+}
+
+func (r *Report) Table() *output.Table {
+	table := &output.Table{
+		RootName: "RateDiff",
+		RowName:  "Change",
+		Columns: []output.Column{
+			{Name: "char_code", XMLName: "CharCode"},
+			{Name: "status", XMLName: "Status"},
+			{Name: "old_rate", XMLName: "OldRate"},
+			{Name: "new_rate", XMLName: "NewRate"},
+			{Name: "change", XMLName: "Change"},
+			{Name: "change_percent", XMLName: "ChangePercent"},
+		},
+		Rows: make([][]any, 0, len(r.Changes)),
+	}
+
+	for _, change := range r.Changes {
+		table.Rows = append(table.Rows, []any{
+			change.CharCode,
+			change.Status,
+			cell(change.OldRate),
+			cell(change.NewRate),
+			cell(change.Delta),
+			cell(change.PercentChange),
+		})
+	}
+
+	return table
+}
+
+// cell turns a missing value into the nil cell the output package renders
+// as null or empty.
+func cell(value *data.CurrencyValue) any {
+	if value == nil {
+		return nil
+	}
+
+	return *value
+}
@@ -0,0 +1,109 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/output"
+)
+
+func valCurs(t *testing.T, date string, unitRates map[string]string) *data.ValCurs {
+	t.Helper()
+
+	result := &data.ValCurs{Date: date, Name: "", Valutes: nil}
+
+	for charCode, text := range unitRates {
+		rate, err := data.ParseCurrencyValue(text)
+		if err != nil {
+			t.Fatalf("ParseCurrencyValue(%q): %v", text, err)
+		}
+
+		result.Valutes = append(result.Valutes, data.Valute{CharCode: charCode, UnitRate: rate})
+	}
+
+	return result
+}
+
+func TestCompareLeavesInapplicableValuesEmpty(t *testing.T) {
+	t.Parallel()
+
+	previous := valCurs(t, "16.10.2026", map[string]string{"USD": "90", "EUR": "100", "ZWL": "0"})
+	current := valCurs(t, "17.10.2026", map[string]string{"USD": "99", "GBP": "0", "ZWL": "1"})
+
+	changes := make(map[string]Change)
+	for _, change := range Compare(previous, current).Changes {
+		changes[change.CharCode] = change
+	}
+
+	tests := []struct {
+		charCode string
+		status   string
+		oldRate  string
+		newRate  string
+		delta    string
+		percent  string
+	}{
+		{charCode: "USD", status: StatusChanged, oldRate: "90", newRate: "99", delta: "9", percent: "10.0000"},
+		{charCode: "ZWL", status: StatusChanged, oldRate: "0", newRate: "1", delta: "1", percent: ""},
+		{charCode: "GBP", status: StatusAdded, oldRate: "", newRate: "0", delta: "", percent: ""},
+		{charCode: "EUR", status: StatusRemoved, oldRate: "100", newRate: "", delta: "", percent: ""},
+	}
+
+	text := func(value *data.CurrencyValue) string {
+		if value == nil {
+			return ""
+		}
+
+		return value.String()
+	}
+
+	for _, test := range tests {
+		change, ok := changes[test.charCode]
+		if !ok {
+			t.Errorf("%s: no change reported", test.charCode)
+
+			continue
+		}
+
+		got := []string{change.Status, text(change.OldRate), text(change.NewRate), text(change.Delta),
+			text(change.PercentChange)}
+		want := []string{test.status, test.oldRate, test.newRate, test.delta, test.percent}
+
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("%s: got %q, want %q", test.charCode, got, want)
+		}
+	}
+}
+
+func TestReportTableWritesNullForMissingRates(t *testing.T) {
+	t.Parallel()
+
+	previous := valCurs(t, "16.10.2026", map[string]string{"EUR": "100.5"})
+	current := valCurs(t, "17.10.2026", map[string]string{"GBP": "0.0"})
+	table := Compare(previous, current).Table()
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: "json", want: []string{`"old_rate": null`, `"new_rate": 0.0`, `"change": null`}},
+		{format: "yaml", want: []string{"old_rate: null", "new_rate: 0.0", "change_percent: null"}},
+		{format: "csv", want: []string{"GBP,added,,0.0,,", "EUR,removed,100.5,,,"}},
+		{format: "xml", want: []string{"<OldRate></OldRate>", "<NewRate>0.0</NewRate>"}},
+	}
+
+	for _, test := range tests {
+		var encoded bytes.Buffer
+		if err := output.Encode(&encoded, test.format, table); err != nil {
+			t.Fatalf("%s: %v", test.format, err)
+		}
+
+		for _, want := range test.want {
+			if !strings.Contains(encoded.String(), want) {
+				t.Errorf("%s output lacks %q:\n%s", test.format, want, encoded.String())
+			}
+		}
+	}
+}
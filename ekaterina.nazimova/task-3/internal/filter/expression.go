@@ -0,0 +1,169 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/UwUshkin/task-3/internal/data"
+)
+
+const (
+	opEqual        = "="
+	opNotEqual     = "!="
+	opLess         = "<"
+	opLessEqual    = "<="
+	opGreater      = ">"
+	opGreaterEqual = ">="
+	opIn           = "in"
+	opNotIn        = "not in"
+)
+
+var (
+	ErrInvalidExpression = errors.New("invalid filter expression")
+	ErrInvalidOperand    = errors.New("invalid filter operand")
+)
+
+type Condition struct {
+	Field    data.Field
+	Operator string
+	Operands []any
+}
+
+func ParseExpression(expression string) (Condition, error) {
+	trimmed := strings.TrimSpace(expression)
+
+	nameEnd := strings.IndexFunc(trimmed, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '_'
+	})
+	if nameEnd <= 0 {
+		return Condition{}, fmt.Errorf("%w: expected <field> <operator> <value>", ErrInvalidExpression)
+	}
+
+	field, err := data.LookupField(trimmed[:nameEnd])
+	if err != nil {
+		return Condition{}, fmt.Errorf("%w: %w", ErrInvalidExpression, err)
+	}
+
+	operator, rest, err := splitOperator(strings.TrimSpace(trimmed[nameEnd:]))
+	if err != nil {
+		return Condition{}, err
+	}
+
+	rawOperands := []string{rest}
+	if operator == opIn || operator == opNotIn {
+		rawOperands = strings.Split(strings.TrimSuffix(strings.TrimPrefix(rest, "("), ")"), ",")
+	}
+
+	operands := make([]any, 0, len(rawOperands))
+
+	for _, raw := range rawOperands {
+		operand, err := parseOperand(field, raw)
+		if err != nil {
+			return Condition{}, err
+		}
+
+		operands = append(operands, operand)
+	}
+
+	return Condition{Field: field, Operator: operator, Operands: operands}, nil
+}
+
+func splitOperator(text string) (string, string, error) {
+	lower := strings.ToLower(text)
+
+	for _, keyword := range []string{opNotIn, opIn} {
+		if strings.HasPrefix(lower, keyword+" ") || strings.HasPrefix(lower, keyword+"(") {
+			return keyword, strings.TrimSpace(text[len(keyword):]), nil
+		}
+	}
+
+	symbols := []string{opGreaterEqual, opLessEqual, opNotEqual, "==", opEqual, opGreater, opLess}
+	for _, symbol := range symbols {
+		if strings.HasPrefix(text, symbol) {
+			if symbol == "==" {
+				symbol = opEqual
+			}
+
+			return symbol, strings.TrimSpace(text[len(symbol):]), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("%w: unknown operator in %q", ErrInvalidExpression, text)
+}
+
+func parseOperand(field data.Field, raw string) (any, error) {
+	text := strings.Trim(strings.TrimSpace(raw), `"'`)
+	if text == "" {
+		return nil, fmt.Errorf("%w: empty value for %s", ErrInvalidOperand, field.Name)
+	}
+
+	switch field.Value(data.Valute{}).(type) {
+	case data.CurrencyValue:
+		parsed, err := data.ParseCurrencyValue(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s expects a number: %w", ErrInvalidOperand, field.Name, err)
+		}
+
+		return parsed, nil
+	case int:
+		parsed, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s expects an integer: %w", ErrInvalidOperand, field.Name, err)
+		}
+
+		return parsed, nil
+	default:
+		return text, nil
+	}
+}
+
+func (c Condition) Match(valute data.Valute) bool {
+	value := c.Field.Value(valute)
+
+	switch c.Operator {
+	case opIn:
+		return c.matchesAny(value)
+	case opNotIn:
+		return !c.matchesAny(value)
+	case opEqual:
+		return equal(value, c.Operands[0])
+	case opNotEqual:
+		return !equal(value, c.Operands[0])
+	}
+
+	cmp := data.CompareValues(value, c.Operands[0])
+
+	switch c.Operator {
+	case opLess:
+		return cmp < 0
+	case opLessEqual:
+		return cmp <= 0
+	case opGreater:
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+func (c Condition) matchesAny(value any) bool {
+	for _, operand := range c.Operands {
+		if equal(value, operand) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func equal(value, operand any) bool {
+	if text, ok := value.(string); ok {
+		operandText, _ := operand.(string)
+
+		return strings.EqualFold(text, operandText)
+	}
+
+	return data.CompareValues(value, operand) == 0
+}
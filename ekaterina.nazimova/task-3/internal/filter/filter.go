@@ -0,0 +1,78 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/data"
+)
+
+type Criteria struct {
+	CharCodes   []string
+	MinValue    *data.CurrencyValue
+	MaxValue    *data.CurrencyValue
+	Expressions []string
+}
+
+func Apply(valutes data.CurrencyList, criteria Criteria) (data.CurrencyList, error) {
+	conditions, err := ParseExpressions(criteria.Expressions)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]struct{}, len(criteria.CharCodes))
+	for _, charCode := range criteria.CharCodes {
+		allowed[strings.ToUpper(strings.TrimSpace(charCode))] = struct{}{}
+	}
+
+	filtered := make(data.CurrencyList, 0, len(valutes))
+
+	for _, valute := range valutes {
+		if len(allowed) > 0 {
+			if _, ok := allowed[strings.ToUpper(valute.CharCode)]; !ok {
+				continue
+			}
+		}
+
+		if criteria.MinValue != nil && valute.Value.Cmp(*criteria.MinValue) < 0 {
+			continue
+		}
+
+		if criteria.MaxValue != nil && valute.Value.Cmp(*criteria.MaxValue) > 0 {
+			continue
+		}
+
+		if !matchAll(conditions, valute) {
+			continue
+		}
+
+		filtered = append(filtered, valute)
+	}
+
+	return filtered, nil
+}
+
+func ParseExpressions(expressions []string) ([]Condition, error) {
+	conditions := make([]Condition, 0, len(expressions))
+
+	for _, expression := range expressions {
+		condition, err := ParseExpression(expression)
+		if err != nil {
+			return nil, fmt.Errorf("parsing filter %q: %w", expression, err)
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, nil
+}
+
+func matchAll(conditions []Condition, valute data.Valute) bool {
+	for _, condition := range conditions {
+		if !condition.Match(valute) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,201 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/UwUshkin/task-3/internal/atomicfile"
+	"github.com/UwUshkin/task-3/internal/data"
+)
+
+const (
+	dirPermissions  = 0o755
+	filePermissions = 0o600
+	snapshotExt     = ".json"
+	keyLayout       = "2006-01-02"
+)
+
+var ErrSnapshotNotFound = errors.New("no snapshot for date")
+
+type Point struct {
+	Date     string             `json:"date"`
+	CharCode string             `json:"char_code"`
+	Nominal  int                `json:"nominal"`
+	Value    data.CurrencyValue `json:"value"`
+	UnitRate data.CurrencyValue `json:"unit_rate"`
+}
+
+type record struct {
+	ID       string             `json:"id"`
+	NumCode  int                `json:"num_code"`
+	CharCode string             `json:"char_code"`
+	Nominal  int                `json:"nominal"`
+	Name     string             `json:"name"`
+	Value    data.CurrencyValue `json:"value"`
+	UnitRate data.CurrencyValue `json:"unit_rate"`
+}
+
+type snapshot struct {
+	Date    string   `json:"date"`
+	Name    string   `json:"name"`
+	Valutes []record `json:"valutes"`
+}
+
+type Store struct {
+	dir string
+}
+
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return nil, fmt.Errorf("creating history directory %q: %w", dir, err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) Put(valCurs *data.ValCurs) (time.Time, error) {
+	date, err := valCurs.ParseDate()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("resolving snapshot key: %w", err)
+	}
+
+	snap := snapshot{
+		Date:    valCurs.Date,
+		Name:    valCurs.Name,
+		Valutes: make([]record, 0, len(valCurs.Valutes)),
+	}
+
+	for _, valute := range valCurs.Valutes {
+		snap.Valutes = append(snap.Valutes, record{
+			ID:       valute.ID,
+			NumCode:  valute.NumCode,
+			CharCode: valute.CharCode,
+			Nominal:  valute.Nominal,
+			Name:     valute.Name,
+			Value:    valute.Value,
+			UnitRate: valute.UnitRate,
+		})
+	}
+
+	jsonData, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("marshalling snapshot: %w", err)
+	}
+
+	snapshotPath := s.path(date)
+	if err := atomicfile.Write(snapshotPath, jsonData, filePermissions, 0); err != nil {
+		return time.Time{}, fmt.Errorf("writing snapshot %q: %w", snapshotPath, err)
+	}
+
+	return date, nil
+}
+
+func (s *Store) Get(date time.Time) (*data.ValCurs, error) {
+	snapshotPath := s.path(date)
+
+	fileData, err := os.ReadFile(snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w %s", ErrSnapshotNotFound, date.Format(data.DateLayout))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot %q: %w", snapshotPath, err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(fileData, &snap); err != nil {
+		return nil, fmt.Errorf("unmarshalling snapshot %q: %w", snapshotPath, err)
+	}
+
+	valCurs := &data.ValCurs{
+		Date:    snap.Date,
+		Name:    snap.Name,
+		Valutes: make(data.CurrencyList, 0, len(snap.Valutes)),
+	}
+
+	for index, rec := range snap.Valutes {
+		valCurs.Valutes = append(valCurs.Valutes, data.Valute{
+			ID:       rec.ID,
+			Nominal:  rec.Nominal,
+			Name:     rec.Name,
+			CharCode: rec.CharCode,
+			NumCode:  rec.NumCode,
+			Value:    rec.Value,
+			UnitRate: rec.UnitRate,
+			Index:    index,
+		})
+	}
+
+	return valCurs, nil
+}
+
+func (s *Store) Dates(from, to time.Time) ([]time.Time, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing history directory %q: %w", s.dir, err)
+	}
+
+	dates := make([]time.Time, 0, len(entries))
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, snapshotExt) {
+			continue
+		}
+
+		date, err := time.Parse(keyLayout, strings.TrimSuffix(name, snapshotExt))
+		if err != nil || date.Before(from) || date.After(to) {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	return dates, nil
+}
+
+func (s *Store) Range(charCode string, from, to time.Time) ([]Point, error) {
+	dates, err := s.Dates(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]Point, 0, len(dates))
+
+	for _, date := range dates {
+		valCurs, err := s.Get(date)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, valute := range valCurs.Valutes {
+			if !strings.EqualFold(valute.CharCode, charCode) {
+				continue
+			}
+
+			points = append(points, Point{
+				Date:     valCurs.Date,
+				CharCode: valute.CharCode,
+				Nominal:  valute.Nominal,
+				Value:    valute.Value,
+				UnitRate: valute.UnitRate,
+			})
+		}
+	}
+
+	return points, nil
+}
+
+func (s *Store) path(date time.Time) string {
+	return filepath.Join(s.dir, date.Format(keyLayout)+snapshotExt)
+}
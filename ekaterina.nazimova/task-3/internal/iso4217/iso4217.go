@@ -0,0 +1,247 @@
+package iso4217
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// NoMinorUnits marks currencies such as XDR for which ISO 4217 defines no
+	// minor unit.
+	NoMinorUnits = -1
+
+	// firstDataLine is the 1-based CSV line of the first row after the header.
+	firstDataLine = 2
+)
+
+var (
+	ErrUnknownCode    = errors.New("unknown ISO 4217 currency code")
+	ErrMissingCode    = errors.New("missing currency code")
+	ErrMissingNumeric = errors.New("missing numeric code")
+)
+
+//go:embed currencies.csv
+var embeddedTable []byte
+
+type Currency struct {
+	Code       string
+	Numeric    int
+	MinorUnits int
+	Name       string
+	Symbol     string
+	// Countries holds the ISO 3166 alpha-2 codes of the issuing countries
+	// and territories.
+	Countries []string
+}
+
+type Table struct {
+	byCode map[string]Currency
+}
+
+//nolint:gochecknoglobals
+var (
+	builtinOnce  sync.Once
+	builtinTable *Table
+	builtinErr   error
+)
+
+// Builtin returns the table compiled into the binary.
+func Builtin() (*Table, error) {
+	builtinOnce.Do(func() {
+		builtinTable, builtinErr = Parse(embeddedTable)
+	})
+
+	return builtinTable, builtinErr
+}
+
+// Load returns the builtin table updated with the rows of the CSV file at
+// path, which uses the embedded layout. An override row changes only the
+// columns it fills in, so "KZT,,,,₸" just sets a symbol; a row for a code
+// missing from the builtin table needs at least its numeric code. An empty
+// path yields the builtin table.
+func Load(path string) (*Table, error) {
+	builtin, err := Builtin()
+	if err != nil || path == "" {
+		return builtin, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading currency catalog %q: %w", path, err)
+	}
+
+	rows, err := readRows(content)
+	if err != nil {
+		return nil, fmt.Errorf("currency catalog %q: %w", path, err)
+	}
+
+	merged := &Table{byCode: make(map[string]Currency, builtin.Len()+len(rows))}
+	for code, currency := range builtin.byCode {
+		merged.byCode[code] = currency
+	}
+
+	for index, row := range rows {
+		currency, err := mergeRecord(merged.byCode, row)
+		if err != nil {
+			return nil, fmt.Errorf("currency catalog %q line %d: %w", path, index+firstDataLine, err)
+		}
+
+		merged.byCode[currency.Code] = currency
+	}
+
+	return merged, nil
+}
+
+// mergeRecord applies the non-empty columns of an override row on top of the
+// known entry for its code.
+func mergeRecord(known map[string]Currency, record []string) (Currency, error) {
+	column := func(index int) string {
+		if index < len(record) {
+			return strings.TrimSpace(record[index])
+		}
+
+		return ""
+	}
+
+	code := strings.ToUpper(column(0))
+	if code == "" {
+		return Currency{}, ErrMissingCode
+	}
+
+	currency, ok := known[code]
+	if !ok {
+		if column(1) == "" {
+			return Currency{}, fmt.Errorf("%w for new currency %s", ErrMissingNumeric, code)
+		}
+
+		currency = Currency{Code: code, Numeric: 0, MinorUnits: NoMinorUnits, Name: "", Symbol: "", Countries: nil}
+	}
+
+	var err error
+
+	if numeric := column(1); numeric != "" {
+		if currency.Numeric, err = strconv.Atoi(numeric); err != nil {
+			return Currency{}, fmt.Errorf("parsing numeric code %q: %w", numeric, err)
+		}
+	}
+
+	if minorUnits := column(2); minorUnits != "" {
+		if currency.MinorUnits, err = strconv.Atoi(minorUnits); err != nil {
+			return Currency{}, fmt.Errorf("parsing minor units %q: %w", minorUnits, err)
+		}
+	}
+
+	if name := column(3); name != "" {
+		currency.Name = name
+	}
+
+	if symbol := column(4); symbol != "" {
+		currency.Symbol = symbol
+	}
+
+	if countries := column(5); countries != "" {
+		currency.Countries = strings.Fields(countries)
+	}
+
+	return currency, nil
+}
+
+// Parse reads a table in the embedded CSV layout: a header row followed by
+// code, numeric, minor_units and name columns, optionally followed by
+// symbol and space-separated countries.
+func Parse(content []byte) (*Table, error) {
+	rows, err := readRows(content)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &Table{byCode: make(map[string]Currency, len(rows))}
+
+	for index, record := range rows {
+		currency, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("ISO 4217 table line %d: %w", index+firstDataLine, err)
+		}
+
+		table.byCode[currency.Code] = currency
+	}
+
+	return table, nil
+}
+
+// readRows returns the CSV records that follow the header row.
+func readRows(content []byte) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading ISO 4217 table: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	return records[1:], nil
+}
+
+func parseRecord(record []string) (Currency, error) {
+	const columns = 4
+
+	if len(record) < columns {
+		return Currency{}, fmt.Errorf("expected %d columns, got %d", columns, len(record))
+	}
+
+	numeric, err := strconv.Atoi(record[1])
+	if err != nil {
+		return Currency{}, fmt.Errorf("parsing numeric code %q: %w", record[1], err)
+	}
+
+	minorUnits := NoMinorUnits
+
+	if record[2] != "" {
+		if minorUnits, err = strconv.Atoi(record[2]); err != nil {
+			return Currency{}, fmt.Errorf("parsing minor units %q: %w", record[2], err)
+		}
+	}
+
+	currency := Currency{
+		Code:       strings.ToUpper(strings.TrimSpace(record[0])),
+		Numeric:    numeric,
+		MinorUnits: minorUnits,
+		Name:       record[3],
+		Symbol:     "",
+		Countries:  nil,
+	}
+
+	if len(record) > columns {
+		currency.Symbol = record[columns]
+	}
+
+	if len(record) > columns+1 {
+		currency.Countries = strings.Fields(record[columns+1])
+	}
+
+	return currency, nil
+}
+
+func (t *Table) Lookup(code string) (Currency, error) {
+	currency, ok := t.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w %q", ErrUnknownCode, code)
+	}
+
+	return currency, nil
+}
+
+func (t *Table) Len() int {
+	return len(t.byCode)
+}
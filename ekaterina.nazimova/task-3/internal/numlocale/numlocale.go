@@ -0,0 +1,194 @@
+package numlocale
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	groupSize = 3
+
+	noBreakSpace       = '\u00a0'
+	narrowNoBreakSpace = '\u202f'
+)
+
+var (
+	ErrUnknownLocale = errors.New("unknown number locale")
+	ErrInvalidNumber = errors.New("invalid localized number")
+)
+
+type Locale struct {
+	Name    string
+	Decimal rune
+
+	// Group is the thousands separator; zero disables grouping on output.
+	Group rune
+
+	// AltGroups lists further separators accepted when parsing, e.g. the
+	// plain and narrow spaces that stand in for a non-breaking one.
+	AltGroups []rune
+
+	// AltDecimals lists decimal separators accepted when parsing a number
+	// that does not contain Decimal, e.g. '.' in feeds that mix notations.
+	AltDecimals []rune
+}
+
+func Russian() Locale {
+	return Locale{
+		Name:        "ru",
+		Decimal:     ',',
+		Group:       noBreakSpace,
+		AltGroups:   []rune{' ', narrowNoBreakSpace},
+		AltDecimals: []rune{'.'},
+	}
+}
+
+func English() Locale {
+	return Locale{Name: "en", Decimal: '.', Group: ',', AltGroups: nil, AltDecimals: nil}
+}
+
+func German() Locale {
+	return Locale{
+		Name:        "de",
+		Decimal:     ',',
+		Group:       '.',
+		AltGroups:   []rune{' ', noBreakSpace, narrowNoBreakSpace},
+		AltDecimals: nil,
+	}
+}
+
+func locales() []Locale {
+	return []Locale{Russian(), English(), German()}
+}
+
+func Names() []string {
+	names := make([]string, 0, len(locales()))
+	for _, locale := range locales() {
+		names = append(names, locale.Name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func Lookup(name string) (Locale, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+
+	for _, locale := range locales() {
+		if locale.Name == normalized {
+			return locale, nil
+		}
+	}
+
+	return Locale{}, fmt.Errorf("%w %q (available: %s)", ErrUnknownLocale, name, strings.Join(Names(), ", "))
+}
+
+func (l Locale) isGroup(r rune) bool {
+	if r == l.Group {
+		return true
+	}
+
+	for _, alt := range l.AltGroups {
+		if r == alt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Normalize converts a number written in the locale into the canonical
+// form with an optional leading minus, no grouping and '.' as the decimal
+// separator.
+func (l Locale) Normalize(text string) (string, error) {
+	trimmed := strings.TrimSpace(text)
+
+	sign := ""
+	if strings.HasPrefix(trimmed, "-") || strings.HasPrefix(trimmed, "+") {
+		sign, trimmed = strings.TrimPrefix(trimmed[:1], "+"), trimmed[1:]
+	}
+
+	integerPart, fractionPart, hasFraction := strings.Cut(trimmed, string(l.Decimal))
+
+	for _, alt := range l.AltDecimals {
+		if hasFraction {
+			break
+		}
+
+		integerPart, fractionPart, hasFraction = strings.Cut(trimmed, string(alt))
+	}
+
+	groups := strings.FieldsFunc(integerPart, l.isGroup)
+	if !validGroups(integerPart, groups) || !isDigits(fractionPart) || hasFraction && fractionPart == "" {
+		return "", fmt.Errorf("%w for locale %s: %q", ErrInvalidNumber, l.Name, text)
+	}
+
+	canonical := sign + strings.Join(groups, "")
+	if hasFraction {
+		canonical += "." + fractionPart
+	}
+
+	return canonical, nil
+}
+
+func validGroups(integerPart string, groups []string) bool {
+	if len(groups) == 0 {
+		return false
+	}
+
+	for idx, group := range groups {
+		if !isDigits(group) || group == "" {
+			return false
+		}
+
+		if len(groups) > 1 && (idx > 0 && len(group) != groupSize || idx == 0 && len(group) > groupSize) {
+			return false
+		}
+	}
+
+	separators := utf8.RuneCountInString(integerPart) - len(strings.Join(groups, ""))
+
+	return separators == len(groups)-1
+}
+
+func isDigits(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Format renders a canonical number (as produced by Normalize or by
+// CurrencyValue.String) with the locale's decimal and group separators.
+func (l Locale) Format(canonical string) string {
+	sign := ""
+	if strings.HasPrefix(canonical, "-") {
+		sign, canonical = "-", canonical[1:]
+	}
+
+	integerPart, fractionPart, hasFraction := strings.Cut(canonical, ".")
+
+	var grouped strings.Builder
+
+	for idx, digit := range integerPart {
+		if l.Group != 0 && idx > 0 && (len(integerPart)-idx)%groupSize == 0 {
+			grouped.WriteRune(l.Group)
+		}
+
+		grouped.WriteRune(digit)
+	}
+
+	if hasFraction {
+		grouped.WriteRune(l.Decimal)
+		grouped.WriteString(fractionPart)
+	}
+
+	return sign + grouped.String()
+}
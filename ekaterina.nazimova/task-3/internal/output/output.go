@@ -0,0 +1,254 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/UwUshkin/task-3/internal/atomicfile"
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/numlocale"
+)
+
+const (
+	DefaultFormat     = "json"
+	outputPermissions = 0o600
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported output format")
+	ErrDuplicateFormat   = errors.New("output format already registered")
+	ErrInvalidFormat     = errors.New("invalid output format definition")
+)
+
+type Encoder interface {
+	Encode(writer io.Writer, table *Table) error
+}
+
+type EncoderFunc func(writer io.Writer, table *Table) error
+
+func (f EncoderFunc) Encode(writer io.Writer, table *Table) error {
+	return f(writer, table)
+}
+
+type Format struct {
+	Name       string
+	Extensions []string
+	Encoder    Encoder
+}
+
+type Table struct {
+	RootName string
+	RowName  string
+	Columns  []Column
+	// Rows holds one cell per column. A nil cell marks a value that does not
+	// apply: JSON and YAML write null, the other formats leave it empty.
+	Rows [][]any
+
+	// NumberLocale, when set, renders decimals in text formats (CSV, TSV,
+	// Markdown) with the locale's separators instead of the canonical form.
+	NumberLocale *numlocale.Locale
+}
+
+type Column struct {
+	Name    string
+	XMLName string
+}
+
+func NewValuteTable(valutes data.CurrencyList, fields []data.Field) *Table {
+	table := &Table{
+		RootName: "ValCurs",
+		RowName:  "Valute",
+		Columns:  make([]Column, 0, len(fields)),
+		Rows:     make([][]any, 0, len(valutes)),
+
+		NumberLocale: nil,
+	}
+
+	for _, field := range fields {
+		table.Columns = append(table.Columns, Column{Name: field.Name, XMLName: field.XMLName})
+	}
+
+	for _, valute := range valutes {
+		row := make([]any, 0, len(fields))
+		for _, field := range fields {
+			row = append(row, field.Value(valute))
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+type registry struct {
+	mu         sync.RWMutex
+	formats    map[string]Format
+	extensions map[string]string
+}
+
+var formats = newRegistry() //nolint:gochecknoglobals
+
+func newRegistry() *registry {
+	reg := &registry{
+		mu:         sync.RWMutex{},
+		formats:    make(map[string]Format),
+		extensions: make(map[string]string),
+	}
+
+	for _, format := range builtinFormats() {
+		if err := reg.register(format); err != nil {
+			panic(err)
+		}
+	}
+
+	return reg
+}
+
+func builtinFormats() []Format {
+	return []Format{
+		{Name: "json", Extensions: []string{".json"}, Encoder: EncoderFunc(encodeJSON)},
+		{Name: "ndjson", Extensions: []string{".ndjson", ".jsonl"}, Encoder: EncoderFunc(encodeNDJSON)},
+		{Name: "yaml", Extensions: []string{".yaml", ".yml"}, Encoder: EncoderFunc(encodeYAML)},
+		{Name: "xml", Extensions: []string{".xml"}, Encoder: EncoderFunc(encodeXML)},
+		{Name: "csv", Extensions: []string{".csv"}, Encoder: delimitedEncoder{comma: ','}},
+		{Name: "tsv", Extensions: []string{".tsv"}, Encoder: delimitedEncoder{comma: '\t'}},
+		{Name: "markdown", Extensions: []string{".md", ".markdown"}, Encoder: EncoderFunc(encodeMarkdown)},
+	}
+}
+
+func (r *registry) register(format Format) error {
+	name := strings.ToLower(format.Name)
+	if name == "" || format.Encoder == nil {
+		return fmt.Errorf("%w: name and encoder are required", ErrInvalidFormat)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.formats[name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateFormat, name)
+	}
+
+	r.formats[name] = format
+
+	for _, ext := range format.Extensions {
+		r.extensions[strings.ToLower(ext)] = name
+	}
+
+	return nil
+}
+
+func Register(format Format) error {
+	return formats.register(format)
+}
+
+func Lookup(name string) (Encoder, error) {
+	formats.mu.RLock()
+	defer formats.mu.RUnlock()
+
+	format, ok := formats.formats[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)",
+			ErrUnsupportedFormat, name, strings.Join(namesLocked(), ", "))
+	}
+
+	return format.Encoder, nil
+}
+
+// Extension returns the preferred file extension of a registered format.
+func Extension(name string) (string, error) {
+	formats.mu.RLock()
+	defer formats.mu.RUnlock()
+
+	format, ok := formats.formats[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("%w: %q (available: %s)",
+			ErrUnsupportedFormat, name, strings.Join(namesLocked(), ", "))
+	}
+
+	if len(format.Extensions) == 0 {
+		return "." + format.Name, nil
+	}
+
+	return format.Extensions[0], nil
+}
+
+func Names() []string {
+	formats.mu.RLock()
+	defer formats.mu.RUnlock()
+
+	return namesLocked()
+}
+
+func namesLocked() []string {
+	names := make([]string, 0, len(formats.formats))
+	for name := range formats.formats {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func Resolve(name, outputPath string) (string, error) {
+	if name != "" {
+		if _, err := Lookup(name); err != nil {
+			return "", err
+		}
+
+		return strings.ToLower(name), nil
+	}
+
+	formats.mu.RLock()
+	defer formats.mu.RUnlock()
+
+	if detected, ok := formats.extensions[strings.ToLower(filepath.Ext(outputPath))]; ok {
+		return detected, nil
+	}
+
+	return DefaultFormat, nil
+}
+
+func Encode(writer io.Writer, format string, table *Table) error {
+	encoder, err := Lookup(format)
+	if err != nil {
+		return err
+	}
+
+	if err := encoder.Encode(writer, table); err != nil {
+		return fmt.Errorf("encoding %s: %w", format, err)
+	}
+
+	return nil
+}
+
+func WriteFile(outputPath, format string, table *Table) error {
+	return WriteFileWithBackups(outputPath, format, table, 0)
+}
+
+// WriteFileWithBackups encodes the table and atomically replaces outputPath,
+// keeping up to backups previous versions next to it.
+func WriteFileWithBackups(outputPath, format string, table *Table, backups int) error {
+	resolved, err := Resolve(format, outputPath)
+	if err != nil {
+		return fmt.Errorf("resolving output format: %w", err)
+	}
+
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, resolved, table); err != nil {
+		return err
+	}
+
+	if err := atomicfile.Write(outputPath, encoded.Bytes(), outputPermissions, backups); err != nil {
+		return fmt.Errorf("writing output file %q: %w", outputPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const indent = "  "
+
+func encodeJSON(writer io.Writer, table *Table) error {
+	var compact bytes.Buffer
+
+	compact.WriteByte('[')
+
+	for idx, row := range table.Rows {
+		if idx > 0 {
+			compact.WriteByte(',')
+		}
+
+		if err := writeJSONObject(&compact, table.Columns, row); err != nil {
+			return err
+		}
+	}
+
+	compact.WriteByte(']')
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, compact.Bytes(), "", indent); err != nil {
+		return fmt.Errorf("indenting JSON: %w", err)
+	}
+
+	if _, err := indented.WriteTo(writer); err != nil {
+		return fmt.Errorf("writing JSON: %w", err)
+	}
+
+	return nil
+}
+
+func encodeNDJSON(writer io.Writer, table *Table) error {
+	buffered := bufio.NewWriter(writer)
+
+	for _, row := range table.Rows {
+		if err := writeJSONObject(buffered, table.Columns, row); err != nil {
+			return err
+		}
+
+		if err := buffered.WriteByte('\n'); err != nil {
+			return fmt.Errorf("writing NDJSON: %w", err)
+		}
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("writing NDJSON: %w", err)
+	}
+
+	return nil
+}
+
+func writeJSONObject(writer io.Writer, columns []Column, row []any) error {
+	var object bytes.Buffer
+
+	object.WriteByte('{')
+
+	for idx, column := range columns {
+		if idx > 0 {
+			object.WriteByte(',')
+		}
+
+		key, err := json.Marshal(column.Name)
+		if err != nil {
+			return fmt.Errorf("marshalling key %q: %w", column.Name, err)
+		}
+
+		value, err := json.Marshal(row[idx])
+		if err != nil {
+			return fmt.Errorf("marshalling %s: %w", column.Name, err)
+		}
+
+		object.Write(key)
+		object.WriteByte(':')
+		object.Write(value)
+	}
+
+	object.WriteByte('}')
+
+	if _, err := object.WriteTo(writer); err != nil {
+		return fmt.Errorf("writing JSON object: %w", err)
+	}
+
+	return nil
+}
+
+func encodeYAML(writer io.Writer, table *Table) error {
+	document := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+
+	for _, row := range table.Rows {
+		mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+		for idx, column := range table.Columns {
+			var valueNode yaml.Node
+			if err := valueNode.Encode(row[idx]); err != nil {
+				return fmt.Errorf("encoding %s: %w", column.Name, err)
+			}
+
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: column.Name},
+				&valueNode,
+			)
+		}
+
+		document.Content = append(document.Content, mapping)
+	}
+
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(len(indent))
+
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("encoding YAML: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("flushing YAML: %w", err)
+	}
+
+	return nil
+}
+
+type xmlRow struct {
+	name    string
+	columns []Column
+	cells   []any
+}
+
+func (x xmlRow) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Space: "", Local: x.name}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return fmt.Errorf("encoding %s start: %w", x.name, err)
+	}
+
+	for idx, column := range x.columns {
+		element := xml.StartElement{Name: xml.Name{Space: "", Local: column.XMLName}, Attr: nil}
+
+		if x.cells[idx] == nil {
+			if err := encoder.EncodeToken(element); err != nil {
+				return fmt.Errorf("encoding %s: %w", column.XMLName, err)
+			}
+
+			if err := encoder.EncodeToken(element.End()); err != nil {
+				return fmt.Errorf("encoding %s: %w", column.XMLName, err)
+			}
+
+			continue
+		}
+
+		if err := encoder.EncodeElement(x.cells[idx], element); err != nil {
+			return fmt.Errorf("encoding %s: %w", column.XMLName, err)
+		}
+	}
+
+	if err := encoder.EncodeToken(start.End()); err != nil {
+		return fmt.Errorf("encoding %s end: %w", x.name, err)
+	}
+
+	return nil
+}
+
+func encodeXML(writer io.Writer, table *Table) error {
+	root := xml.StartElement{Name: xml.Name{Space: "", Local: table.RootName}, Attr: nil}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return fmt.Errorf("writing XML header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", indent)
+
+	if err := encoder.EncodeToken(root); err != nil {
+		return fmt.Errorf("encoding %s start: %w", table.RootName, err)
+	}
+
+	for _, row := range table.Rows {
+		if err := encoder.Encode(xmlRow{name: table.RowName, columns: table.Columns, cells: row}); err != nil {
+			return fmt.Errorf("encoding XML: %w", err)
+		}
+	}
+
+	if err := encoder.EncodeToken(root.End()); err != nil {
+		return fmt.Errorf("encoding %s end: %w", table.RootName, err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("flushing XML: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/numlocale"
+)
+
+type delimitedEncoder struct {
+	comma rune
+}
+
+func (d delimitedEncoder) Encode(writer io.Writer, table *Table) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = d.comma
+
+	if err := csvWriter.Write(headerRow(table.Columns)); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for idx, row := range table.Rows {
+		if err := csvWriter.Write(formatRow(row, table.NumberLocale)); err != nil {
+			return fmt.Errorf("writing row %d: %w", idx, err)
+		}
+	}
+
+	csvWriter.Flush()
+
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("flushing rows: %w", err)
+	}
+
+	return nil
+}
+
+func encodeMarkdown(writer io.Writer, table *Table) error {
+	buffered := bufio.NewWriter(writer)
+
+	separators := make([]string, len(table.Columns))
+	for idx := range separators {
+		separators[idx] = "---"
+	}
+
+	writeMarkdownRow(buffered, headerRow(table.Columns))
+	writeMarkdownRow(buffered, separators)
+
+	for _, row := range table.Rows {
+		writeMarkdownRow(buffered, formatRow(row, table.NumberLocale))
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("writing Markdown table: %w", err)
+	}
+
+	return nil
+}
+
+func writeMarkdownRow(writer *bufio.Writer, cells []string) {
+	escaped := make([]string, len(cells))
+	for idx, cell := range cells {
+		escaped[idx] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+
+	_, _ = writer.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+}
+
+func headerRow(columns []Column) []string {
+	header := make([]string, len(columns))
+	for idx, column := range columns {
+		header[idx] = column.Name
+	}
+
+	return header
+}
+
+func formatRow(row []any, locale *numlocale.Locale) []string {
+	cells := make([]string, len(row))
+	for idx, value := range row {
+		cells[idx] = formatCell(value, locale)
+	}
+
+	return cells
+}
+
+func formatCell(value any, locale *numlocale.Locale) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case data.CurrencyValue:
+		if locale != nil {
+			return typed.Format(*locale)
+		}
+
+		return typed.String()
+	case string:
+		return typed
+	case int:
+		return strconv.Itoa(typed)
+	case fmt.Stringer:
+		return typed.String()
+	default:
+		return fmt.Sprint(typed)
+	}
+}
@@ -0,0 +1,110 @@
+// Package pipeline splits a conversion into a Source that produces
+// currencies, a chain of Transformers and one or more Sinks. Each stage can
+// be replaced by an in-memory fake.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/data"
+)
+
+type Source interface {
+	Read(ctx context.Context) (*data.ValCurs, error)
+}
+
+// Transformer returns a new list; it must not modify its input, since the
+// same decoded data may feed several sinks.
+type Transformer interface {
+	Transform(ctx context.Context, valutes data.CurrencyList) (data.CurrencyList, error)
+}
+
+type Sink interface {
+	Write(ctx context.Context, valutes data.CurrencyList) error
+}
+
+type SourceFunc func(ctx context.Context) (*data.ValCurs, error)
+
+func (f SourceFunc) Read(ctx context.Context) (*data.ValCurs, error) {
+	return f(ctx)
+}
+
+type TransformerFunc func(ctx context.Context, valutes data.CurrencyList) (data.CurrencyList, error)
+
+func (f TransformerFunc) Transform(ctx context.Context, valutes data.CurrencyList) (data.CurrencyList, error) {
+	return f(ctx, valutes)
+}
+
+type SinkFunc func(ctx context.Context, valutes data.CurrencyList) error
+
+func (f SinkFunc) Write(ctx context.Context, valutes data.CurrencyList) error {
+	return f(ctx, valutes)
+}
+
+type Pipeline struct {
+	Source       Source
+	Transformers []Transformer
+	Sinks        []Sink
+}
+
+// Run reads once, applies the transformers in order and hands the result to
+// every sink. Errors are tagged with the stage they came from.
+func (p *Pipeline) Run(ctx context.Context) error {
+	valCurs, err := p.Source.Read(ctx)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrDecode, err)
+	}
+
+	valutes, err := Chain(p.Transformers...).Transform(ctx, valCurs.Valutes)
+	if err != nil {
+		return apperr.Wrap(apperr.ErrTransform, err)
+	}
+
+	for _, sink := range p.Sinks {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("pipeline cancelled: %w", err)
+		}
+
+		if err := sink.Write(ctx, valutes); err != nil {
+			return apperr.Wrap(apperr.ErrEncode, err)
+		}
+	}
+
+	return nil
+}
+
+// TransformSink applies transformers to the data before handing it to sink,
+// e.g. to give one of several sinks its own sort order.
+func TransformSink(sink Sink, transformers ...Transformer) Sink {
+	chain := Chain(transformers...)
+
+	return SinkFunc(func(ctx context.Context, valutes data.CurrencyList) error {
+		transformed, err := chain.Transform(ctx, valutes)
+		if err != nil {
+			return apperr.Wrap(apperr.ErrTransform, err)
+		}
+
+		return sink.Write(ctx, transformed)
+	})
+}
+
+// Chain composes transformers into one that applies them left to right,
+// stopping between them once ctx is cancelled.
+func Chain(transformers ...Transformer) Transformer {
+	return TransformerFunc(func(ctx context.Context, valutes data.CurrencyList) (data.CurrencyList, error) {
+		for _, transformer := range transformers {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("transforming cancelled: %w", err)
+			}
+
+			var err error
+			if valutes, err = transformer.Transform(ctx, valutes); err != nil {
+				return nil, err
+			}
+		}
+
+		return valutes, nil
+	})
+}
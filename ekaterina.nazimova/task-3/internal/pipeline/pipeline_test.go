@@ -0,0 +1,269 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/data"
+)
+
+func testValutes(t *testing.T) data.CurrencyList {
+	t.Helper()
+
+	var valutes data.CurrencyList
+
+	for _, rate := range []struct{ charCode, value string }{
+		{charCode: "USD", value: "90.2834"},
+		{charCode: "EUR", value: "98.1234"},
+		{charCode: "JPY", value: "0.605012"},
+	} {
+		value, err := data.ParseCurrencyValue(rate.value)
+		if err != nil {
+			t.Fatalf("ParseCurrencyValue(%q): %v", rate.value, err)
+		}
+
+		valutes = append(valutes, data.Valute{CharCode: rate.charCode, Nominal: 1, Value: value, UnitRate: value})
+	}
+
+	return valutes
+}
+
+func staticSource(valutes data.CurrencyList) Source {
+	return SourceFunc(func(context.Context) (*data.ValCurs, error) {
+		return &data.ValCurs{Date: "17.10.2026", Name: "Foreign Currency Market", Valutes: valutes}, nil
+	})
+}
+
+// recorder is a sink that keeps a deep copy of every list it is given.
+type recorder struct {
+	writes []data.CurrencyList
+}
+
+func (r *recorder) sink() Sink {
+	return SinkFunc(func(_ context.Context, valutes data.CurrencyList) error {
+		r.writes = append(r.writes, append(data.CurrencyList{}, valutes...))
+
+		return nil
+	})
+}
+
+func charCodes(valutes data.CurrencyList) string {
+	codes := make([]string, 0, len(valutes))
+	for _, valute := range valutes {
+		codes = append(codes, valute.CharCode)
+	}
+
+	return strings.Join(codes, ",")
+}
+
+func TestRunAppliesTransformersInOrder(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+
+	step := func(name string) Transformer {
+		return TransformerFunc(func(_ context.Context, valutes data.CurrencyList) (data.CurrencyList, error) {
+			calls = append(calls, name)
+
+			return valutes[1:], nil
+		})
+	}
+
+	var got recorder
+
+	pipe := &Pipeline{
+		Source:       staticSource(testValutes(t)),
+		Transformers: []Transformer{step("first"), step("second")},
+		Sinks:        []Sink{got.sink()},
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if strings.Join(calls, ",") != "first,second" {
+		t.Errorf("transformer calls = %v, want [first second]", calls)
+	}
+
+	if len(got.writes) != 1 || charCodes(got.writes[0]) != "JPY" {
+		t.Errorf("sink writes = %v, want one write of JPY", got.writes)
+	}
+}
+
+func TestRunDoesNotShareMutationsAcrossSinks(t *testing.T) {
+	t.Parallel()
+
+	decoded := testValutes(t)
+	original := append(data.CurrencyList{}, decoded...)
+
+	var rounded, sorted, plain recorder
+
+	pipe := &Pipeline{
+		Source:       staticSource(decoded),
+		Transformers: nil,
+		Sinks: []Sink{
+			TransformSink(rounded.sink(), &RoundTransformer{Places: 0, Mode: data.RoundHalfUp}),
+			TransformSink(sorted.sink(), &SortTransformer{Spec: "value:desc"}),
+			plain.sink(),
+		},
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if len(rounded.writes) != 1 || rounded.writes[0][0].Value.String() != "90" {
+		t.Errorf("rounding sink got %+v, want values rounded to integers", rounded.writes)
+	}
+
+	if len(sorted.writes) != 1 || charCodes(sorted.writes[0]) != "EUR,USD,JPY" {
+		t.Errorf("sorting sink got %+v, want EUR,USD,JPY", sorted.writes)
+	}
+
+	if len(plain.writes) != 1 || !reflect.DeepEqual(plain.writes[0], original) {
+		t.Errorf("plain sink got %+v, want the decoded list %+v", plain.writes, original)
+	}
+
+	if !reflect.DeepEqual(decoded, original) {
+		t.Errorf("decoded list was modified to %+v", decoded)
+	}
+}
+
+func TestRunTagsErrorsWithStage(t *testing.T) {
+	t.Parallel()
+
+	errBoom := errors.New("boom")
+
+	failingSource := SourceFunc(func(context.Context) (*data.ValCurs, error) {
+		return nil, errBoom
+	})
+	failingTransformer := TransformerFunc(func(context.Context, data.CurrencyList) (data.CurrencyList, error) {
+		return nil, errBoom
+	})
+	failingSink := SinkFunc(func(context.Context, data.CurrencyList) error {
+		return errBoom
+	})
+
+	var unused recorder
+
+	tests := []struct {
+		name  string
+		pipe  *Pipeline
+		stage error
+	}{
+		{
+			name:  "source",
+			pipe:  &Pipeline{Source: failingSource, Transformers: nil, Sinks: []Sink{unused.sink()}},
+			stage: apperr.ErrDecode,
+		},
+		{
+			name: "transformer",
+			pipe: &Pipeline{
+				Source:       staticSource(testValutes(t)),
+				Transformers: []Transformer{failingTransformer},
+				Sinks:        []Sink{unused.sink()},
+			},
+			stage: apperr.ErrTransform,
+		},
+		{
+			name: "sink",
+			pipe: &Pipeline{
+				Source:       staticSource(testValutes(t)),
+				Transformers: nil,
+				Sinks:        []Sink{failingSink},
+			},
+			stage: apperr.ErrEncode,
+		},
+		{
+			name: "sink transformer",
+			pipe: &Pipeline{
+				Source:       staticSource(testValutes(t)),
+				Transformers: nil,
+				Sinks:        []Sink{TransformSink(unused.sink(), failingTransformer)},
+			},
+			stage: apperr.ErrTransform,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.pipe.Run(context.Background())
+		if !errors.Is(err, errBoom) {
+			t.Errorf("%s: Run error = %v, want %v", test.name, err, errBoom)
+		}
+
+		if stage := apperr.Stage(err); !errors.Is(stage, test.stage) {
+			t.Errorf("%s: stage = %v, want %v", test.name, stage, test.stage)
+		}
+	}
+
+	if len(unused.writes) != 0 {
+		t.Errorf("sinks after a failed stage were written %d time(s)", len(unused.writes))
+	}
+}
+
+func TestRunStopsOnCancellation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("before transformers", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		transformed := false
+		transformer := TransformerFunc(func(_ context.Context, valutes data.CurrencyList) (data.CurrencyList, error) {
+			transformed = true
+
+			return valutes, nil
+		})
+
+		var got recorder
+
+		pipe := &Pipeline{
+			Source:       staticSource(testValutes(t)),
+			Transformers: []Transformer{transformer},
+			Sinks:        []Sink{got.sink()},
+		}
+
+		if err := pipe.Run(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Run error = %v, want context.Canceled", err)
+		}
+
+		if transformed || len(got.writes) != 0 {
+			t.Errorf("cancelled run transformed = %t, wrote %d time(s)", transformed, len(got.writes))
+		}
+	})
+
+	t.Run("between sinks", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var first, second recorder
+
+		cancelling := SinkFunc(func(ctx context.Context, valutes data.CurrencyList) error {
+			cancel()
+
+			return first.sink().Write(ctx, valutes)
+		})
+
+		pipe := &Pipeline{
+			Source:       staticSource(testValutes(t)),
+			Transformers: nil,
+			Sinks:        []Sink{cancelling, second.sink()},
+		}
+
+		if err := pipe.Run(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Run error = %v, want context.Canceled", err)
+		}
+
+		if len(first.writes) != 1 || len(second.writes) != 0 {
+			t.Errorf("sink writes = %d and %d, want 1 and 0", len(first.writes), len(second.writes))
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/filter"
+	"github.com/UwUshkin/task-3/internal/iso4217"
+	"github.com/UwUshkin/task-3/internal/numlocale"
+	"github.com/UwUshkin/task-3/internal/output"
+	"github.com/UwUshkin/task-3/internal/sorter"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+// DecoderSource decodes a CBR XML input spec. In lenient mode the skipped
+// elements are passed to OnIssues, if set, before Read returns.
+type DecoderSource struct {
+	Spec     string
+	Options  xmldecoder.Options
+	OnIssues func(issues []xmldecoder.Issue) error
+}
+
+func (s *DecoderSource) Read(ctx context.Context) (*data.ValCurs, error) {
+	valCurs, issues, err := xmldecoder.DecodeWithIssuesContext(ctx, s.Spec, s.Options)
+	if err != nil {
+		return nil, fmt.Errorf("decoding XML: %w", err)
+	}
+
+	if s.OnIssues != nil && s.Options.Mode == xmldecoder.ModeLenient {
+		if err := s.OnIssues(issues); err != nil {
+			return nil, err
+		}
+	}
+
+	return valCurs, nil
+}
+
+// EnrichTransformer attaches ISO 4217 metadata to every currency and marks
+// codes missing from Catalog as unknown.
+type EnrichTransformer struct {
+	Catalog *iso4217.Table
+}
+
+func (t *EnrichTransformer) Transform(_ context.Context, valutes data.CurrencyList) (data.CurrencyList, error) {
+	enriched := append(data.CurrencyList{}, valutes...)
+
+	for idx := range enriched {
+		currency, err := t.Catalog.Lookup(enriched[idx].CharCode)
+		if err != nil {
+			enriched[idx].Info = data.CurrencyInfo{
+				Status:      data.ISOStatusUnknown,
+				EnglishName: "",
+				MinorUnits:  iso4217.NoMinorUnits,
+				Symbol:      "",
+				Countries:   nil,
+			}
+
+			continue
+		}
+
+		enriched[idx].Info = data.CurrencyInfo{
+			Status:      data.ISOStatusKnown,
+			EnglishName: currency.Name,
+			MinorUnits:  currency.MinorUnits,
+			Symbol:      currency.Symbol,
+			Countries:   currency.Countries,
+		}
+	}
+
+	return enriched, nil
+}
+
+type FilterTransformer struct {
+	Criteria filter.Criteria
+}
+
+func (t *FilterTransformer) Transform(_ context.Context, valutes data.CurrencyList) (data.CurrencyList, error) {
+	filtered, err := filter.Apply(valutes, t.Criteria)
+	if err != nil {
+		return nil, fmt.Errorf("filtering currencies: %w", err)
+	}
+
+	return filtered, nil
+}
+
+type SortTransformer struct {
+	Spec string
+}
+
+func (t *SortTransformer) Transform(_ context.Context, valutes data.CurrencyList) (data.CurrencyList, error) {
+	sorted := append(data.CurrencyList{}, valutes...)
+
+	if err := sorter.Sort(sorted, t.Spec); err != nil {
+		return nil, fmt.Errorf("sorting currencies: %w", err)
+	}
+
+	return sorted, nil
+}
+
+// RoundTransformer normalizes Value and UnitRate to a fixed number of
+// decimal places.
+type RoundTransformer struct {
+	Places int
+	Mode   data.RoundingMode
+}
+
+func (t *RoundTransformer) Transform(_ context.Context, valutes data.CurrencyList) (data.CurrencyList, error) {
+	rounded := append(data.CurrencyList{}, valutes...)
+	rounded.Round(t.Places, t.Mode)
+
+	return rounded, nil
+}
+
+// FileSink projects the currencies onto Fields and writes them atomically
+// in Format, which defaults to JSON or the file extension when empty.
+type FileSink struct {
+	Path         string
+	Format       string
+	Fields       []data.Field
+	Backups      int
+	NumberLocale *numlocale.Locale
+}
+
+func (s *FileSink) Write(ctx context.Context, valutes data.CurrencyList) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("saving results to %q: %w", s.Path, err)
+	}
+
+	table := output.NewValuteTable(valutes, s.Fields)
+	table.NumberLocale = s.NumberLocale
+
+	if err := output.WriteFileWithBackups(s.Path, s.Format, table, s.Backups); err != nil {
+		return fmt.Errorf("saving results: %w", err)
+	}
+
+	return nil
+}
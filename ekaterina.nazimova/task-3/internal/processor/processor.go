@@ -1,31 +1,149 @@
 package processor
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os"
-	"sort"
 
+	"github.com/UwUshkin/task-3/internal/apperr"
+	"github.com/UwUshkin/task-3/internal/config"
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/filter"
+	"github.com/UwUshkin/task-3/internal/numlocale"
+	"github.com/UwUshkin/task-3/internal/output"
+	"github.com/UwUshkin/task-3/internal/pipeline"
 	"github.com/UwUshkin/task-3/internal/xmldecoder"
 )
 
-const outputPermissions = 0o600
+func ProcessAndSave(cfg *config.Config) error {
+	return Run(context.Background(), cfg)
+}
+
+// Run converts the input of cfg into every configured output, giving up
+// between stages once ctx is cancelled.
+func Run(ctx context.Context, cfg *config.Config) error {
+	pipe, err := NewPipeline(cfg)
+	if err != nil {
+		return err
+	}
+
+	return pipe.Run(ctx)
+}
+
+// NewPipeline builds the default pipeline for cfg: decode, enrich and filter
+// once, then sort, round and write separately for every configured output.
+func NewPipeline(cfg *config.Config) (*pipeline.Pipeline, error) {
+	if err := cfg.RequireOutput(); err != nil {
+		return nil, apperr.Wrap(apperr.ErrConfig, err)
+	}
+
+	opts, err := DecoderOptions(cfg)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.ErrConfig, err)
+	}
+
+	var rounding pipeline.Transformer
+
+	if cfg.Precision != config.PrecisionAsIs {
+		mode, err := data.ParseRoundingMode(cfg.Rounding)
+		if err != nil {
+			return nil, apperr.Wrap(apperr.ErrConfig, fmt.Errorf("resolving rounding mode: %w", err))
+		}
+
+		rounding = &pipeline.RoundTransformer{Places: cfg.Precision, Mode: mode}
+	}
+
+	catalog, err := cfg.LoadCatalog()
+	if err != nil {
+		return nil, apperr.Wrap(apperr.ErrConfig, err)
+	}
+
+	outputs := cfg.ResolvedOutputs()
+	sinks := make([]pipeline.Sink, 0, len(outputs))
 
-func ProcessAndSave(inputPath, outputPath string) error {
-	valCursData, err := xmldecoder.DecodeCBRXML(inputPath)
+	for _, out := range outputs {
+		sink, err := newFileSink(cfg, out)
+		if err != nil {
+			return nil, apperr.Wrap(apperr.ErrConfig, err)
+		}
+
+		transformers := []pipeline.Transformer{&pipeline.SortTransformer{Spec: out.Sort}}
+		if rounding != nil {
+			transformers = append(transformers, rounding)
+		}
+
+		sinks = append(sinks, pipeline.TransformSink(sink, transformers...))
+	}
+
+	return &pipeline.Pipeline{
+		Source: &pipeline.DecoderSource{
+			Spec:    cfg.InputFile,
+			Options: opts,
+			OnIssues: func(issues []xmldecoder.Issue) error {
+				return writeIssues(outputs[0], issues)
+			},
+		},
+		Transformers: []pipeline.Transformer{
+			&pipeline.EnrichTransformer{Catalog: catalog},
+			&pipeline.FilterTransformer{Criteria: filter.Criteria{
+				CharCodes:   cfg.Filters.CharCodes,
+				MinValue:    cfg.Filters.MinValue,
+				MaxValue:    cfg.Filters.MaxValue,
+				Expressions: cfg.Filters.Expressions,
+			}},
+		},
+		Sinks: sinks,
+	}, nil
+}
+
+func DecoderOptions(cfg *config.Config) (xmldecoder.Options, error) {
+	opts := xmldecoder.DefaultOptions()
+
+	locale, err := numlocale.Lookup(cfg.InputLocale)
+	if err != nil {
+		return opts, fmt.Errorf("resolving input locale: %w", err)
+	}
+
+	mode, err := xmldecoder.ParseMode(cfg.DecodeMode)
 	if err != nil {
-		return fmt.Errorf("decoding XML from %q: %w", inputPath, err)
+		return opts, fmt.Errorf("resolving decode mode: %w", err)
 	}
 
-	sort.Sort(valCursData.Valutes)
+	opts.Locale = locale
+	opts.Charset = cfg.InputEncoding
+	opts.Mode = mode
 
-	jsonData, err := json.MarshalIndent(valCursData.Valutes, "", "  ")
+	return opts, nil
+}
+
+func newFileSink(cfg *config.Config, out config.Output) (*pipeline.FileSink, error) {
+	fields, err := data.LookupFields(out.Fields)
 	if err != nil {
-		return fmt.Errorf("marshalling results to JSON: %w", err)
+		return nil, fmt.Errorf("resolving fields of %q: %w", out.File, err)
 	}
 
-	if err := os.WriteFile(outputPath, jsonData, outputPermissions); err != nil {
-		return fmt.Errorf("writing output file %q: %w", outputPath, err)
+	sink := &pipeline.FileSink{
+		Path:         out.File,
+		Format:       out.Format,
+		Fields:       fields,
+		Backups:      cfg.OutputBackups,
+		NumberLocale: nil,
+	}
+
+	if cfg.OutputLocale != "" {
+		locale, err := numlocale.Lookup(cfg.OutputLocale)
+		if err != nil {
+			return nil, fmt.Errorf("resolving output locale: %w", err)
+		}
+
+		sink.NumberLocale = &locale
+	}
+
+	return sink, nil
+}
+
+func writeIssues(primary config.Output, issues []xmldecoder.Issue) error {
+	if err := output.WriteFile(ReportPath(primary.File), primary.Format, issuesTable(issues)); err != nil {
+		return apperr.Wrap(apperr.ErrEncode, fmt.Errorf("saving validation report: %w", err))
 	}
 
 	return nil
@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/output"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+const reportSuffix = ".report"
+
+// ReportPath places the validation report next to the output file, keeping
+// its extension: out/rates.json becomes out/rates.report.json.
+func ReportPath(outputPath string) string {
+	ext := filepath.Ext(outputPath)
+
+	return strings.TrimSuffix(outputPath, ext) + reportSuffix + ext
+}
+
+func issuesTable(issues []xmldecoder.Issue) *output.Table {
+	table := &output.Table{
+		RootName: "ValidationReport",
+		RowName:  "Issue",
+		Columns: []output.Column{
+			{Name: "source", XMLName: "Source"},
+			{Name: "index", XMLName: "Index"},
+			{Name: "id", XMLName: "ID"},
+			{Name: "reason", XMLName: "Reason"},
+		},
+		Rows:         make([][]any, 0, len(issues)),
+		NumberLocale: nil,
+	}
+
+	for _, issue := range issues {
+		table.Rows = append(table.Rows, []any{issue.Source, issue.Index, issue.ID, issue.Reason})
+	}
+
+	return table
+}
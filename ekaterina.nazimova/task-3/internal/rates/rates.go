@@ -0,0 +1,114 @@
+package rates
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+const (
+	BaseCurrency    = "RUB"
+	ConversionScale = 6
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrInvalidRate     = errors.New("invalid rate")
+)
+
+type quote struct {
+	value   data.CurrencyValue
+	nominal data.CurrencyValue
+}
+
+type Converter struct {
+	date     string
+	quotes   map[string]quote
+	scale    int
+	rounding data.RoundingMode
+}
+
+func New(valCurs *data.ValCurs) (*Converter, error) {
+	quotes := make(map[string]quote, len(valCurs.Valutes)+1)
+	quotes[BaseCurrency] = quote{value: data.CurrencyValueFromInt(1), nominal: data.CurrencyValueFromInt(1)}
+
+	for _, valute := range valCurs.Valutes {
+		if valute.Nominal <= 0 || valute.Value.Sign() <= 0 {
+			return nil, fmt.Errorf("%w for %s: value %v per %d units",
+				ErrInvalidRate, valute.CharCode, valute.Value, valute.Nominal)
+		}
+
+		quotes[strings.ToUpper(valute.CharCode)] = quote{
+			value:   valute.Value,
+			nominal: data.CurrencyValueFromInt(valute.Nominal),
+		}
+	}
+
+	return &Converter{
+		date:     valCurs.Date,
+		quotes:   quotes,
+		scale:    ConversionScale,
+		rounding: data.RoundHalfUp,
+	}, nil
+}
+
+func Load(filePath string, opts xmldecoder.Options) (*Converter, error) {
+	valCurs, err := xmldecoder.DecodeFile(filePath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("decoding XML from %q: %w", filePath, err)
+	}
+
+	return New(valCurs)
+}
+
+func (c *Converter) Date() string {
+	return c.date
+}
+
+func (c *Converter) quote(charCode string) (quote, error) {
+	found, ok := c.quotes[strings.ToUpper(charCode)]
+	if !ok {
+		return quote{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, charCode)
+	}
+
+	return found, nil
+}
+
+func (c *Converter) UnitRate(charCode string) (data.CurrencyValue, error) {
+	found, err := c.quote(charCode)
+	if err != nil {
+		return data.CurrencyValue{}, err
+	}
+
+	rate, err := found.value.Quo(found.nominal, c.scale, c.rounding)
+	if err != nil {
+		return data.CurrencyValue{}, fmt.Errorf("%w for %s: %w", ErrInvalidRate, charCode, err)
+	}
+
+	return rate, nil
+}
+
+func (c *Converter) Convert(amount data.CurrencyValue, from, to string) (data.CurrencyValue, error) {
+	source, err := c.quote(from)
+	if err != nil {
+		return data.CurrencyValue{}, fmt.Errorf("resolving source currency: %w", err)
+	}
+
+	target, err := c.quote(to)
+	if err != nil {
+		return data.CurrencyValue{}, fmt.Errorf("resolving target currency: %w", err)
+	}
+
+	numerator := amount.Mul(source.value).Mul(target.nominal)
+	denominator := source.nominal.Mul(target.value)
+
+	result, err := numerator.Quo(denominator, c.scale, c.rounding)
+	if err != nil {
+		return data.CurrencyValue{}, fmt.Errorf("%w for %s: %w", ErrInvalidRate, to, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/rates"
+	"github.com/UwUshkin/task-3/internal/source"
+	"github.com/UwUshkin/task-3/internal/xmldecoder"
+)
+
+var (
+	errMissingParameter = errors.New("missing query parameter")
+	errReloadFailed     = errors.New("reload failed")
+)
+
+type snapshot struct {
+	valCurs   *data.ValCurs
+	converter *rates.Converter
+	version   inputVersion
+}
+
+type fileState struct {
+	path    string
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// inputVersion fingerprints the files an input spec resolves to. Stdin has
+// no files, so once read it never looks changed.
+type inputVersion []fileState
+
+func statInput(spec string) inputVersion {
+	paths := source.Paths(spec)
+	version := make(inputVersion, 0, len(paths))
+
+	for _, path := range paths {
+		state := fileState{path: path, exists: false, modTime: time.Time{}, size: 0}
+
+		if info, err := os.Stat(path); err == nil {
+			state.exists, state.modTime, state.size = true, info.ModTime(), info.Size()
+		}
+
+		version = append(version, state)
+	}
+
+	return version
+}
+
+func (v inputVersion) equal(other inputVersion) bool {
+	return slices.EqualFunc(v, other, func(a, b fileState) bool {
+		return a.path == b.path && a.exists == b.exists && a.size == b.size && a.modTime.Equal(b.modTime)
+	})
+}
+
+type Server struct {
+	inputSpec string
+	opts      xmldecoder.Options
+
+	mu      sync.RWMutex
+	current *snapshot
+	// failed is the version of the input that last failed to decode, so a
+	// broken file is not decoded again on every request.
+	failed inputVersion
+}
+
+type ratesResponse struct {
+	Date    string            `json:"date"`
+	Valutes data.CurrencyList `json:"valutes"`
+}
+
+type convertResponse struct {
+	Date   string             `json:"date"`
+	From   string             `json:"from"`
+	To     string             `json:"to"`
+	Amount data.CurrencyValue `json:"amount"`
+	Result data.CurrencyValue `json:"result"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New serves the rates decoded from the input spec with opts. The spec may be
+// anything the export accepts: a file, a directory, a glob, an archive or
+// "-" for stdin.
+func New(inputSpec string, opts xmldecoder.Options) (*Server, error) {
+	srv := &Server{
+		inputSpec: inputSpec,
+		opts:      opts,
+		mu:        sync.RWMutex{},
+		current:   nil,
+		failed:    nil,
+	}
+
+	if _, err := srv.Reload(); err != nil {
+		return nil, err
+	}
+
+	return srv, nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /rates", s.handleRates)
+	mux.HandleFunc("GET /rates/{char_code}", s.handleRate)
+	mux.HandleFunc("GET /convert", s.handleConvert)
+	mux.HandleFunc("POST /reload", s.handleReload)
+
+	return mux
+}
+
+// Reload decodes the input again when the set of files it resolves to, or
+// the modification time or size of one of them, has changed. Unlike the
+// reload done on every request, it also retries a version of the input that
+// previously failed to decode.
+func (s *Server) Reload() (bool, error) {
+	return s.reload(true)
+}
+
+func (s *Server) reload(retryFailed bool) (bool, error) {
+	version := statInput(s.inputSpec)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != nil && s.current.version.equal(version) {
+		return false, nil
+	}
+
+	if !retryFailed && s.failed != nil && s.failed.equal(version) {
+		return false, fmt.Errorf("%w: input %q is unchanged since the last failed reload",
+			errReloadFailed, s.inputSpec)
+	}
+
+	valCurs, err := xmldecoder.DecodeFile(s.inputSpec, s.opts)
+	if err != nil {
+		s.failed = version
+
+		return false, fmt.Errorf("decoding XML from %q: %w", s.inputSpec, err)
+	}
+
+	converter, err := rates.New(valCurs)
+	if err != nil {
+		s.failed = version
+
+		return false, fmt.Errorf("building converter: %w", err)
+	}
+
+	s.failed = nil
+
+	sort.Sort(valCurs.Valutes)
+
+	s.current = &snapshot{
+		valCurs:   valCurs,
+		converter: converter,
+		version:   version,
+	}
+
+	return true, nil
+}
+
+// snapshot reloads the input when it has changed. A failed reload is logged
+// and the last good snapshot keeps being served.
+func (s *Server) snapshot() (*snapshot, error) {
+	_, err := s.reload(false)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err != nil {
+		if s.current == nil {
+			return nil, err
+		}
+
+		if !errors.Is(err, errReloadFailed) {
+			log.Printf("serving the last good rates: %v", err)
+		}
+	}
+
+	return s.current, nil
+}
+
+func (s *Server) handleRates(writer http.ResponseWriter, _ *http.Request) {
+	snap, err := s.snapshot()
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, ratesResponse{
+		Date:    snap.valCurs.Date,
+		Valutes: snap.valCurs.Valutes,
+	})
+}
+
+func (s *Server) handleRate(writer http.ResponseWriter, request *http.Request) {
+	snap, err := s.snapshot()
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	charCode := request.PathValue("char_code")
+
+	for _, valute := range snap.valCurs.Valutes {
+		if strings.EqualFold(valute.CharCode, charCode) {
+			writeJSON(writer, http.StatusOK, valute)
+
+			return
+		}
+	}
+
+	writeError(writer, http.StatusNotFound, fmt.Errorf("%w: %q", rates.ErrUnknownCurrency, charCode))
+}
+
+func (s *Server) handleConvert(writer http.ResponseWriter, request *http.Request) {
+	snap, err := s.snapshot()
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	query := request.URL.Query()
+	from, target := query.Get("from"), query.Get("to")
+
+	if from == "" || target == "" {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("%w: from and to are required", errMissingParameter))
+
+		return
+	}
+
+	amount := data.CurrencyValueFromInt(1)
+
+	if amountStr := query.Get("amount"); amountStr != "" {
+		if amount, err = data.ParseCurrencyValue(amountStr); err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("parsing amount %q: %w", amountStr, err))
+
+			return
+		}
+	}
+
+	result, err := snap.converter.Convert(amount, from, target)
+	if err != nil {
+		writeError(writer, http.StatusNotFound, err)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, convertResponse{
+		Date:   snap.converter.Date(),
+		From:   strings.ToUpper(from),
+		To:     strings.ToUpper(target),
+		Amount: amount,
+		Result: result,
+	})
+}
+
+func (s *Server) handleReload(writer http.ResponseWriter, _ *http.Request) {
+	reloaded, err := s.Reload()
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]bool{"reloaded": reloaded})
+}
+
+func writeJSON(writer http.ResponseWriter, status int, payload any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	_ = encoder.Encode(payload)
+}
+
+func writeError(writer http.ResponseWriter, status int, err error) {
+	writeJSON(writer, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,153 @@
+package sorter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/data"
+)
+
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	keySeparator   = ","
+	orderSeparator = ":"
+)
+
+var (
+	ErrInvalidOrder = errors.New("invalid sort order")
+	ErrEmptySpec    = errors.New("empty sort spec")
+)
+
+type Key struct {
+	Field      data.Field
+	Descending bool
+}
+
+type Spec []Key
+
+func ParseOrder(order string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case OrderAsc:
+		return false, nil
+	case OrderDesc:
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w %q (expected %s or %s)", ErrInvalidOrder, order, OrderAsc, OrderDesc)
+	}
+}
+
+func ParseSpec(spec string) (Spec, error) {
+	var keys Spec
+
+	for _, part := range strings.Split(spec, keySeparator) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, order, hasOrder := strings.Cut(part, orderSeparator)
+
+		field, err := data.LookupField(name)
+		if err != nil {
+			return nil, fmt.Errorf("parsing sort key %q: %w", part, err)
+		}
+
+		descending := false
+
+		if hasOrder {
+			if descending, err = ParseOrder(order); err != nil {
+				return nil, fmt.Errorf("parsing sort key %q: %w", part, err)
+			}
+		}
+
+		keys = append(keys, Key{Field: field, Descending: descending})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w %q", ErrEmptySpec, spec)
+	}
+
+	return keys, nil
+}
+
+func (s Spec) String() string {
+	parts := make([]string, 0, len(s))
+
+	for _, key := range s {
+		order := OrderAsc
+		if key.Descending {
+			order = OrderDesc
+		}
+
+		parts = append(parts, key.Field.Name+orderSeparator+order)
+	}
+
+	return strings.Join(parts, keySeparator)
+}
+
+func (s Spec) withTieBreakers() Spec {
+	keys := append(Spec{}, s...)
+
+	for _, name := range []string{"char_code", "source", "id"} {
+		if keys.has(name) {
+			continue
+		}
+
+		field, err := data.LookupField(name)
+		if err == nil {
+			keys = append(keys, Key{Field: field, Descending: false})
+		}
+	}
+
+	return keys
+}
+
+func (s Spec) has(name string) bool {
+	for _, key := range s {
+		if key.Field.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Spec) Compare(left, right data.Valute) int {
+	for _, key := range s {
+		cmp := data.CompareValues(key.Field.Value(left), key.Field.Value(right))
+		if cmp == 0 {
+			continue
+		}
+
+		if key.Descending {
+			return -cmp
+		}
+
+		return cmp
+	}
+
+	return 0
+}
+
+func (s Spec) Sort(valutes data.CurrencyList) {
+	keys := s.withTieBreakers()
+
+	sort.SliceStable(valutes, func(i, j int) bool {
+		return keys.Compare(valutes[i], valutes[j]) < 0
+	})
+}
+
+func Sort(valutes data.CurrencyList, spec string) error {
+	keys, err := ParseSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	keys.Sort(valutes)
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package source
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	Stdin = "-"
+
+	xmlExt  = ".xml"
+	gzipExt = ".gz"
+	zipExt  = ".zip"
+
+	entrySeparator = "!"
+	globMeta       = "*?["
+)
+
+var (
+	ErrNoDocuments = errors.New("no XML documents found")
+	ErrUnsupported = errors.New("unsupported input source")
+	ErrNoZipEntry  = errors.New("no such zip entry")
+)
+
+type Document struct {
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+func Resolve(spec string) ([]Document, error) {
+	if spec == Stdin {
+		return []Document{stdinDocument()}, nil
+	}
+
+	paths := []string{spec}
+
+	if strings.ContainsAny(spec, globMeta) {
+		matches, err := filepath.Glob(spec)
+		if err != nil {
+			return nil, fmt.Errorf("expanding glob %q: %w", spec, err)
+		}
+
+		sort.Strings(matches)
+		paths = matches
+	}
+
+	var documents []Document
+
+	for _, path := range paths {
+		resolved, err := resolvePath(path, len(paths) > 1)
+		if err != nil {
+			return nil, err
+		}
+
+		documents = append(documents, resolved...)
+	}
+
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("%w in %q", ErrNoDocuments, spec)
+	}
+
+	return documents, nil
+}
+
+// Paths lists the files on disk that spec currently resolves to: the glob
+// matches, the supported files below a directory, the archive itself for a
+// zip. A plain path is returned even when it does not exist yet, while a
+// malformed or unmatched glob yields nothing. Stdin has no paths.
+func Paths(spec string) []string {
+	if spec == Stdin {
+		return nil
+	}
+
+	paths := []string{spec}
+
+	if strings.ContainsAny(spec, globMeta) {
+		matches, err := filepath.Glob(spec)
+		if err != nil {
+			return nil
+		}
+
+		paths = matches
+	}
+
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			if len(paths) == 1 || isSupported(path) {
+				files = append(files, path)
+			}
+
+			continue
+		}
+
+		_ = filepath.WalkDir(path, func(name string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && isSupported(name) {
+				files = append(files, name)
+			}
+
+			return nil
+		})
+	}
+
+	sort.Strings(files)
+
+	return files
+}
+
+func isSupported(path string) bool {
+	lowerName := strings.ToLower(path)
+
+	return strings.HasSuffix(lowerName, xmlExt) || strings.HasSuffix(lowerName, gzipExt) ||
+		strings.HasSuffix(lowerName, zipExt)
+}
+
+func resolvePath(path string, skipUnsupported bool) ([]Document, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening input %q: %w", path, err)
+	}
+
+	if info.IsDir() {
+		return resolveDir(path)
+	}
+
+	switch lowerName := strings.ToLower(path); {
+	case strings.HasSuffix(lowerName, zipExt):
+		return resolveZip(path)
+	case strings.HasSuffix(lowerName, gzipExt):
+		return []Document{gzipDocument(path)}, nil
+	case strings.HasSuffix(lowerName, xmlExt) || !skipUnsupported:
+		return []Document{fileDocument(path)}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func resolveDir(dir string) ([]Document, error) {
+	var documents []Document
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		resolved, err := resolvePath(path, true)
+		if err != nil {
+			return err
+		}
+
+		documents = append(documents, resolved...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking directory %q: %w", dir, err)
+	}
+
+	return documents, nil
+}
+
+func resolveZip(path string) ([]Document, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening zip archive %q: %w", path, err)
+	}
+	defer archive.Close()
+
+	var documents []Document
+
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name), xmlExt) {
+			continue
+		}
+
+		documents = append(documents, zipEntryDocument(path, entry.Name))
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].Name < documents[j].Name
+	})
+
+	return documents, nil
+}
+
+func stdinDocument() Document {
+	return Document{
+		Name: Stdin,
+		Open: func() (io.ReadCloser, error) {
+			return io.NopCloser(os.Stdin), nil
+		},
+	}
+}
+
+func fileDocument(path string) Document {
+	return Document{
+		Name: path,
+		Open: func() (io.ReadCloser, error) {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("opening XML file %q: %w", path, err)
+			}
+
+			return file, nil
+		},
+	}
+}
+
+func gzipDocument(path string) Document {
+	return Document{
+		Name: path,
+		Open: func() (io.ReadCloser, error) {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("opening gzip file %q: %w", path, err)
+			}
+
+			decompressed, err := gzip.NewReader(file)
+			if err != nil {
+				_ = file.Close()
+
+				return nil, fmt.Errorf("reading gzip header of %q: %w", path, err)
+			}
+
+			return &stackedCloser{Reader: decompressed, closers: []io.Closer{decompressed, file}}, nil
+		},
+	}
+}
+
+func zipEntryDocument(archivePath, entryName string) Document {
+	return Document{
+		Name: archivePath + entrySeparator + entryName,
+		Open: func() (io.ReadCloser, error) {
+			archive, err := zip.OpenReader(archivePath)
+			if err != nil {
+				return nil, fmt.Errorf("opening zip archive %q: %w", archivePath, err)
+			}
+
+			// archive.Open rejects names that are not valid fs paths, such as
+			// "./rates.xml" or Windows-style separators, so look the entry up
+			// by its raw name instead.
+			index := slices.IndexFunc(archive.File, func(file *zip.File) bool {
+				return file.Name == entryName
+			})
+			if index < 0 {
+				_ = archive.Close()
+
+				return nil, fmt.Errorf("opening %q in %q: %w", entryName, archivePath, ErrNoZipEntry)
+			}
+
+			entry, err := archive.File[index].Open()
+			if err != nil {
+				_ = archive.Close()
+
+				return nil, fmt.Errorf("opening %q in %q: %w", entryName, archivePath, err)
+			}
+
+			return &stackedCloser{Reader: entry, closers: []io.Closer{entry, archive}}, nil
+		},
+	}
+}
+
+type stackedCloser struct {
+	io.Reader
+
+	closers []io.Closer
+}
+
+func (s *stackedCloser) Close() error {
+	var errs []error
+
+	for _, closer := range s.closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
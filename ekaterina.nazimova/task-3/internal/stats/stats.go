@@ -0,0 +1,335 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/output"
+)
+
+const (
+	DefaultWindow = 7
+
+	// Scale is the minimum number of decimal places kept by derived figures;
+	// averages keep more when the rates themselves are more precise.
+	Scale         = 6
+	percentFactor = 100
+	sqrtPrecision = 128
+)
+
+var ErrInvalidWindow = errors.New("moving average window must be positive")
+
+type Point struct {
+	Date          time.Time
+	UnitRate      data.CurrencyValue
+	MovingAverage data.CurrencyValue
+	// Complete is false while the series starts less than window days
+	// before Date; the average then covers only the days seen so far.
+	Complete bool
+}
+
+// Summary aggregates the unit rate of one currency. StdDev is the population
+// standard deviation; Volatility is the standard deviation of day-over-day
+// changes in percent. MovingAverage averages the rates dated within the last
+// window calendar days, so days without a document do not stretch it.
+type Summary struct {
+	CharCode      string
+	Count         int
+	From          time.Time
+	To            time.Time
+	Min           data.CurrencyValue
+	Max           data.CurrencyValue
+	Mean          data.CurrencyValue
+	StdDev        data.CurrencyValue
+	Volatility    data.CurrencyValue
+	MovingAverage data.CurrencyValue
+}
+
+type Report struct {
+	Window    int
+	Summaries []Summary
+	Series    map[string][]Point
+}
+
+// Accumulator collects unit rates one currency at a time, so that reports
+// over long archives do not need every decoded document in memory. When
+// several documents carry the same date, the later one wins. Currencies are
+// matched by CharCode regardless of its case.
+type Accumulator struct {
+	rates map[string]map[time.Time]data.CurrencyValue
+}
+
+func NewAccumulator() *Accumulator {
+	return &Accumulator{rates: make(map[string]map[time.Time]data.CurrencyValue)}
+}
+
+// Add records the unit rate of valute on its document Date.
+func (a *Accumulator) Add(valute data.Valute) error {
+	date, err := data.ParseDate(valute.Date)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", valute.Source, valute.CharCode, err)
+	}
+
+	charCode := strings.ToUpper(valute.CharCode)
+
+	if a.rates[charCode] == nil {
+		a.rates[charCode] = make(map[time.Time]data.CurrencyValue)
+	}
+
+	a.rates[charCode][date] = valute.UnitRate
+
+	return nil
+}
+
+// Report builds per-currency statistics from the rates added so far.
+func (a *Accumulator) Report(window int) (*Report, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidWindow, window)
+	}
+
+	report := &Report{
+		Window:    window,
+		Summaries: make([]Summary, 0, len(a.rates)),
+		Series:    make(map[string][]Point, len(a.rates)),
+	}
+
+	for charCode, byDate := range a.rates {
+		series, err := movingAverages(byDate, window)
+		if err != nil {
+			return nil, fmt.Errorf("computing moving average for %s: %w", charCode, err)
+		}
+
+		summary, err := summarize(charCode, series)
+		if err != nil {
+			return nil, fmt.Errorf("summarizing %s: %w", charCode, err)
+		}
+
+		report.Series[charCode] = series
+		report.Summaries = append(report.Summaries, summary)
+	}
+
+	sort.Slice(report.Summaries, func(i, j int) bool {
+		return report.Summaries[i].CharCode < report.Summaries[j].CharCode
+	})
+
+	return report, nil
+}
+
+// movingAverages averages, for every day d, the rates dated in
+// (d - window days, d].
+func movingAverages(byDate map[time.Time]data.CurrencyValue, window int) ([]Point, error) {
+	series := make([]Point, 0, len(byDate))
+	for date, unitRate := range byDate {
+		series = append(series, Point{Date: date, UnitRate: unitRate, MovingAverage: data.CurrencyValue{}, Complete: false})
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Date.Before(series[j].Date)
+	})
+
+	sum := data.CurrencyValue{}
+	first := 0
+
+	for i := range series {
+		sum = sum.Add(series[i].UnitRate)
+
+		windowStart := series[i].Date.AddDate(0, 0, -window)
+		for !series[first].Date.After(windowStart) {
+			sum = sum.Sub(series[first].UnitRate)
+			first++
+		}
+
+		size := i - first + 1
+
+		average, err := sum.Quo(data.CurrencyValueFromInt(size), max(Scale, sum.Scale()), data.RoundHalfUp)
+		if err != nil {
+			return nil, err
+		}
+
+		series[i].MovingAverage = average
+		series[i].Complete = !series[0].Date.After(series[i].Date.AddDate(0, 0, -(window - 1)))
+	}
+
+	return series, nil
+}
+
+func summarize(charCode string, series []Point) (Summary, error) {
+	values := make([]data.CurrencyValue, 0, len(series))
+	for _, point := range series {
+		values = append(values, point.UnitRate)
+	}
+
+	mean, stdDev, err := meanAndStdDev(values)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	changes := make([]data.CurrencyValue, 0, len(values))
+
+	for i := 1; i < len(values); i++ {
+		if values[i-1].IsZero() {
+			continue
+		}
+
+		change, err := values[i].Sub(values[i-1]).Mul(data.CurrencyValueFromInt(percentFactor)).
+			Quo(values[i-1], Scale, data.RoundHalfUp)
+		if err != nil {
+			return Summary{}, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	_, volatility, err := meanAndStdDev(changes)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{
+		CharCode:      charCode,
+		Count:         len(series),
+		From:          series[0].Date,
+		To:            series[len(series)-1].Date,
+		Min:           values[0],
+		Max:           values[0],
+		Mean:          mean,
+		StdDev:        stdDev,
+		Volatility:    volatility,
+		MovingAverage: series[len(series)-1].MovingAverage,
+	}
+
+	for _, value := range values[1:] {
+		if value.Cmp(summary.Min) < 0 {
+			summary.Min = value
+		}
+
+		if value.Cmp(summary.Max) > 0 {
+			summary.Max = value
+		}
+	}
+
+	return summary, nil
+}
+
+func meanAndStdDev(values []data.CurrencyValue) (data.CurrencyValue, data.CurrencyValue, error) {
+	if len(values) == 0 {
+		return data.CurrencyValue{}, data.CurrencyValue{}, nil
+	}
+
+	count := data.CurrencyValueFromInt(len(values))
+	sum := data.CurrencyValue{}
+
+	for _, value := range values {
+		sum = sum.Add(value)
+	}
+
+	exactScale := sum.Scale() + Scale
+	mean, err := sum.Quo(count, exactScale, data.RoundHalfUp)
+	if err != nil {
+		return data.CurrencyValue{}, data.CurrencyValue{}, err
+	}
+
+	squares := data.CurrencyValue{}
+
+	for _, value := range values {
+		deviation := value.Sub(mean)
+		squares = squares.Add(deviation.Mul(deviation))
+	}
+
+	variance, err := squares.Quo(count, 2*exactScale, data.RoundHalfUp)
+	if err != nil {
+		return data.CurrencyValue{}, data.CurrencyValue{}, err
+	}
+
+	stdDev, err := sqrt(variance)
+	if err != nil {
+		return data.CurrencyValue{}, data.CurrencyValue{}, err
+	}
+
+	return mean.Round(max(Scale, sum.Scale()), data.RoundHalfUp), stdDev, nil
+}
+
+func sqrt(value data.CurrencyValue) (data.CurrencyValue, error) {
+	radicand, _, err := big.ParseFloat(value.String(), 10, sqrtPrecision, big.ToNearestEven)
+	if err != nil {
+		return data.CurrencyValue{}, fmt.Errorf("parsing %v: %w", value, err)
+	}
+
+	root := new(big.Float).SetPrec(sqrtPrecision).Sqrt(radicand)
+
+	return data.ParseCurrencyValue(root.Text('f', Scale))
+}
+
+func (r *Report) Table() *output.Table {
+	table := &output.Table{
+		RootName: "RateStats",
+		RowName:  "Currency",
+		Columns: []output.Column{
+			{Name: "char_code", XMLName: "CharCode"},
+			{Name: "count", XMLName: "Count"},
+			{Name: "from", XMLName: "From"},
+			{Name: "to", XMLName: "To"},
+			{Name: "min", XMLName: "Min"},
+			{Name: "max", XMLName: "Max"},
+			{Name: "mean", XMLName: "Mean"},
+			{Name: "stddev", XMLName: "StdDev"},
+			{Name: "volatility_percent", XMLName: "VolatilityPercent"},
+			{Name: "moving_average", XMLName: "MovingAverage"},
+		},
+		Rows:         make([][]any, 0, len(r.Summaries)),
+		NumberLocale: nil,
+	}
+
+	for _, summary := range r.Summaries {
+		table.Rows = append(table.Rows, []any{
+			summary.CharCode,
+			summary.Count,
+			summary.From.Format(data.DateLayout),
+			summary.To.Format(data.DateLayout),
+			summary.Min,
+			summary.Max,
+			summary.Mean,
+			summary.StdDev,
+			summary.Volatility,
+			summary.MovingAverage,
+		})
+	}
+
+	return table
+}
+
+// SeriesTable lists the moving average for every currency and day.
+func (r *Report) SeriesTable() *output.Table {
+	table := &output.Table{
+		RootName: "RateSeries",
+		RowName:  "Point",
+		Columns: []output.Column{
+			{Name: "char_code", XMLName: "CharCode"},
+			{Name: "date", XMLName: "Date"},
+			{Name: "unit_rate", XMLName: "UnitRate"},
+			{Name: "moving_average", XMLName: "MovingAverage"},
+			{Name: "complete_window", XMLName: "CompleteWindow"},
+		},
+		Rows:         nil,
+		NumberLocale: nil,
+	}
+
+	for _, summary := range r.Summaries {
+		for _, point := range r.Series[summary.CharCode] {
+			table.Rows = append(table.Rows, []any{
+				summary.CharCode,
+				point.Date.Format(data.DateLayout),
+				point.UnitRate,
+				point.MovingAverage,
+				point.Complete,
+			})
+		}
+	}
+
+	return table
+}
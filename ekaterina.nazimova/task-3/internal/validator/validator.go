@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/iso4217"
+)
+
+const (
+	RuleMissingDate       = "missing-date"
+	RuleDuplicateCharCode = "duplicate-char-code"
+	RuleNonPositiveValue  = "non-positive-value"
+	RuleZeroNominal       = "zero-nominal"
+	RuleUnknownCurrency   = "unknown-currency"
+	RuleNumCodeMismatch   = "num-code-mismatch"
+)
+
+// NoIndex is used for violations that concern the whole ValCurs rather than
+// a single Valute.
+const NoIndex = -1
+
+type Violation struct {
+	Source   string
+	Index    int
+	ID       string
+	CharCode string
+	Rule     string
+	Message  string
+}
+
+func (v Violation) String() string {
+	var location strings.Builder
+
+	if v.Source != "" {
+		location.WriteString(v.Source + ": ")
+	}
+
+	if v.Index != NoIndex {
+		fmt.Fprintf(&location, "Valute[%d] (ID %s, %s): ", v.Index, v.ID, v.CharCode)
+	}
+
+	return location.String() + v.Rule + ": " + v.Message
+}
+
+type Validator struct {
+	currencies *iso4217.Table
+}
+
+func New(currencies *iso4217.Table) *Validator {
+	return &Validator{currencies: currencies}
+}
+
+// Validate checks one decoded document. It reports every violation instead
+// of stopping at the first one.
+func (v *Validator) Validate(source string, valCurs *data.ValCurs) []Violation {
+	var violations []Violation
+
+	if strings.TrimSpace(valCurs.Date) == "" {
+		violations = append(violations, documentViolation(source, RuleMissingDate, "ValCurs has no Date attribute"))
+	} else if _, err := valCurs.ParseDate(); err != nil {
+		violations = append(violations, documentViolation(source, RuleMissingDate, err.Error()))
+	}
+
+	firstIndex := make(map[string]int, len(valCurs.Valutes))
+
+	// Index the violations by the Valute's position in the source document,
+	// not in valCurs.Valutes, so they line up with the decoder's issues even
+	// when lenient decoding skipped elements.
+	for _, valute := range valCurs.Valutes {
+		report := func(rule, format string, args ...any) {
+			violations = append(violations, Violation{
+				Source:   source,
+				Index:    valute.Index,
+				ID:       valute.ID,
+				CharCode: valute.CharCode,
+				Rule:     rule,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		charCode := strings.ToUpper(valute.CharCode)
+
+		if first, seen := firstIndex[charCode]; seen {
+			report(RuleDuplicateCharCode, "CharCode %s already used by Valute[%d]", valute.CharCode, first)
+		} else {
+			firstIndex[charCode] = valute.Index
+		}
+
+		if valute.Value.Sign() <= 0 {
+			report(RuleNonPositiveValue, "Value %v must be positive", valute.Value)
+		}
+
+		if valute.Nominal <= 0 {
+			report(RuleZeroNominal, "Nominal %d must be positive", valute.Nominal)
+		}
+
+		currency, err := v.currencies.Lookup(valute.CharCode)
+		if err != nil {
+			report(RuleUnknownCurrency, "%v", err)
+
+			continue
+		}
+
+		if currency.Numeric != valute.NumCode {
+			report(RuleNumCodeMismatch, "NumCode %03d does not match ISO 4217 %03d for %s",
+				valute.NumCode, currency.Numeric, currency.Code)
+		}
+	}
+
+	return violations
+}
+
+func documentViolation(source, rule, message string) Violation {
+	return Violation{Source: source, Index: NoIndex, ID: "", CharCode: "", Rule: rule, Message: message}
+}
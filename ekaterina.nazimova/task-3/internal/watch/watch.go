@@ -0,0 +1,135 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultInterval = time.Second
+	DefaultDebounce = 500 * time.Millisecond
+)
+
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{exists: false, modTime: time.Time{}, size: 0}
+	}
+
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+func (s fileState) equal(other fileState) bool {
+	return s.exists == other.exists && s.size == other.size && s.modTime.Equal(other.modTime)
+}
+
+// Poller detects modifications of a set of files by polling their mtime and
+// size. The set comes from targets and is expanded again on every poll, so
+// files that appear or disappear count as changes too. A burst of writes is
+// reported once, after the files have been quiet for the debounce period.
+type Poller struct {
+	interval time.Duration
+	debounce time.Duration
+	targets  func() []string
+	states   map[string]fileState
+}
+
+func NewPoller(targets func() []string, interval, debounce time.Duration) *Poller {
+	poller := &Poller{interval: interval, debounce: debounce, targets: targets, states: nil}
+	poller.Rescan()
+
+	return poller
+}
+
+// Rescan expands the targets again without reporting anything, e.g. after
+// the caller has already acted on a change of the targets themselves. Files
+// already watched keep their last seen state so that a change is not lost.
+func (p *Poller) Rescan() {
+	paths := p.targets()
+	states := make(map[string]fileState, len(paths))
+
+	for _, path := range paths {
+		if state, ok := p.states[path]; ok {
+			states[path] = state
+
+			continue
+		}
+
+		states[path] = stat(path)
+	}
+
+	p.states = states
+}
+
+// poll expands the targets and returns the paths that were added, removed
+// or modified since the previous poll.
+func (p *Poller) poll() []string {
+	paths := p.targets()
+	states := make(map[string]fileState, len(paths))
+
+	var changed []string
+
+	for _, path := range paths {
+		current := stat(path)
+		states[path] = current
+
+		if previous, ok := p.states[path]; !ok || !current.equal(previous) {
+			changed = append(changed, path)
+		}
+	}
+
+	for path := range p.states {
+		if _, ok := states[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	p.states = states
+
+	return changed
+}
+
+// Run blocks until ctx is cancelled, calling onChange with the sorted list of
+// paths that changed since the previous call.
+func (p *Poller) Run(ctx context.Context, onChange func(changed []string)) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	pending := make(map[string]bool)
+
+	var lastChange time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, path := range p.poll() {
+				pending[path] = true
+				lastChange = now
+			}
+
+			if len(pending) == 0 || now.Sub(lastChange) < p.debounce {
+				continue
+			}
+
+			changed := make([]string, 0, len(pending))
+			for path := range pending {
+				changed = append(changed, path)
+			}
+
+			sort.Strings(changed)
+			clear(pending)
+
+			onChange(changed)
+		}
+	}
+}
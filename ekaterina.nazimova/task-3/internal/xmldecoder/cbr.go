@@ -1,48 +1,176 @@
 package xmldecoder
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
-	"os"
 
+	"github.com/UwUshkin/task-3/internal/charset"
 	"github.com/UwUshkin/task-3/internal/data"
-	"golang.org/x/text/encoding/charmap"
+	"github.com/UwUshkin/task-3/internal/source"
 )
 
-var ErrUnsupportedCharset = errors.New("unsupported charset")
+var ErrUnsupportedCharset = charset.ErrUnsupportedCharset
 
-func decodeXMLFromReader(reader io.Reader) (*data.ValCurs, error) {
-	decoder := xml.NewDecoder(reader)
+func newXMLDecoder(reader io.Reader, opts Options) (*xml.Decoder, error) {
+	input, err := charset.NewReader(reader, opts.Charset)
+	if err != nil {
+		return nil, fmt.Errorf("resolving input charset: %w", err)
+	}
+
+	decoder := xml.NewDecoder(input)
+	decoder.CharsetReader = input.XMLCharsetReader()
+
+	return decoder, nil
+}
+
+func decodeStream(stream *Stream) (*data.ValCurs, []Issue, error) {
+	var valutes data.CurrencyList
+
+	for stream.Next() {
+		valutes = append(valutes, stream.Valute())
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, nil, fmt.Errorf("decoding XML structure: %w", err)
+	}
+
+	result := stream.Header()
+	result.Valutes = valutes
+
+	return result, stream.Issues(), nil
+}
+
+// DecodeFile decodes every document the input spec resolves to and merges
+// them into one ValCurs. The merged Date is kept only when all documents agree.
+func DecodeFile(spec string, opts Options) (*data.ValCurs, error) {
+	merged, _, err := DecodeWithIssues(spec, opts)
+
+	return merged, err
+}
 
-	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
-		if charset == "windows-1251" {
-			return charmap.Windows1251.NewDecoder().Reader(input), nil
+// DecodeWithIssues is DecodeFile that also returns the Valute elements
+// skipped in lenient mode.
+func DecodeWithIssues(spec string, opts Options) (*data.ValCurs, []Issue, error) {
+	return DecodeWithIssuesContext(context.Background(), spec, opts)
+}
+
+// DecodeWithIssuesContext is DecodeWithIssues that stops before the next
+// document once ctx is cancelled.
+func DecodeWithIssuesContext(ctx context.Context, spec string, opts Options) (*data.ValCurs, []Issue, error) {
+	documents, issues, err := decodeAll(ctx, spec, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged := &data.ValCurs{Date: documents[0].Date, Name: documents[0].Name, Valutes: nil}
+
+	for _, document := range documents {
+		if document.Date != merged.Date {
+			merged.Date = ""
 		}
 
-		return nil, fmt.Errorf("decoding %w: %s", ErrUnsupportedCharset, charset)
+		merged.Valutes = append(merged.Valutes, document.Valutes...)
 	}
 
-	var result data.ValCurs
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("decoding XML structure: %w", err)
+	return merged, issues, nil
+}
+
+func decodeAll(ctx context.Context, spec string, opts Options) ([]*data.ValCurs, []Issue, error) {
+	documents, err := source.Resolve(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving input %q: %w", spec, err)
 	}
 
-	return &result, nil
+	results := make([]*data.ValCurs, 0, len(documents))
+
+	var issues []Issue
+
+	for _, document := range documents {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, fmt.Errorf("decoding %s: %w", document.Name, err)
+		}
+
+		valCurs, documentIssues, err := DecodeDocumentWithIssues(document, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		results = append(results, valCurs)
+		issues = append(issues, documentIssues...)
+	}
+
+	return results, issues, nil
 }
 
-func DecodeCBRXML(filePath string) (*data.ValCurs, error) {
-	xmlFile, err := os.Open(filePath)
+// Walk decodes the documents of the input spec one Valute at a time and hands
+// each to visit, so memory use does not grow with the size of the input.
+// It returns the Valute elements skipped in lenient mode.
+func Walk(spec string, opts Options, visit func(valute data.Valute) error) ([]Issue, error) {
+	documents, err := source.Resolve(spec)
 	if err != nil {
-		return nil, fmt.Errorf("opening XML file %q: %w", filePath, err)
+		return nil, fmt.Errorf("resolving input %q: %w", spec, err)
+	}
+
+	var issues []Issue
+
+	for _, document := range documents {
+		documentIssues, err := walkDocument(document, opts, visit)
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, documentIssues...)
+	}
+
+	return issues, nil
+}
+
+func walkDocument(document source.Document, opts Options, visit func(valute data.Valute) error) (issues []Issue, err error) {
+	stream, err := OpenDocument(document, opts)
+	if err != nil {
+		return nil, err
 	}
 
 	defer func() {
-		if closeErr := xmlFile.Close(); closeErr != nil && err == nil {
-			err = closeErr
+		err = errors.Join(err, stream.Close())
+	}()
+
+	for stream.Next() {
+		if err := visit(stream.Valute()); err != nil {
+			return nil, err
 		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("%s: decoding XML structure: %w", document.Name, err)
+	}
+
+	return stream.Issues(), nil
+}
+
+func DecodeDocument(document source.Document, opts Options) (*data.ValCurs, error) {
+	valCurs, _, err := DecodeDocumentWithIssues(document, opts)
+
+	return valCurs, err
+}
+
+func DecodeDocumentWithIssues(document source.Document, opts Options) (valCurs *data.ValCurs, issues []Issue, err error) {
+	stream, err := OpenDocument(document, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		err = errors.Join(err, stream.Close())
 	}()
 
-	return decodeXMLFromReader(xmlFile)
+	valCurs, issues, err = decodeStream(stream)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", document.Name, err)
+	}
+
+	return valCurs, issues, nil
 }
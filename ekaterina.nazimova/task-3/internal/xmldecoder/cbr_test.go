@@ -0,0 +1,176 @@
+package xmldecoder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/UwUshkin/task-3/internal/data"
+)
+
+func TestDecodeFileCharsets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		file    string
+		charset string
+	}{
+		{file: "cp1251.xml", charset: ""},
+		{file: "koi8-r.xml", charset: ""},
+		{file: "utf-16le-bom.xml", charset: ""},
+		{file: "utf-16be-bom.xml", charset: ""},
+		{file: "utf-8-bom.xml", charset: ""},
+		{file: "utf-8.xml", charset: ""},
+		{file: "windows-1251.xml", charset: ""},
+		{file: "windows-1251-uppercase.xml", charset: ""},
+		{file: "undeclared-windows-1251.xml", charset: "windows-1251"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			t.Parallel()
+
+			opts := DefaultOptions()
+			opts.Charset = test.charset
+
+			valCurs, err := DecodeFile(filepath.Join("..", "..", "testdata", "charsets", test.file), opts)
+			if err != nil {
+				t.Fatalf("DecodeFile: %v", err)
+			}
+
+			if len(valCurs.Valutes) != 2 {
+				t.Fatalf("got %d valutes, want 2", len(valCurs.Valutes))
+			}
+
+			want := []struct {
+				charCode string
+				name     string
+				value    string
+			}{
+				{charCode: "USD", name: "Доллар США", value: "90.2834"},
+				{charCode: "JPY", name: "Японских иен", value: "60.5012"},
+			}
+
+			for idx, expected := range want {
+				valute := valCurs.Valutes[idx]
+
+				if valute.CharCode != expected.charCode {
+					t.Errorf("valute %d: CharCode = %q, want %q", idx, valute.CharCode, expected.charCode)
+				}
+
+				if valute.Name != expected.name {
+					t.Errorf("%s: Name = %q, want %q", expected.charCode, valute.Name, expected.name)
+				}
+
+				if got := valute.Value.String(); got != expected.value {
+					t.Errorf("%s: Value = %s, want %s", expected.charCode, got, expected.value)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeFileUndeclaredCharsetNeedsOverride(t *testing.T) {
+	t.Parallel()
+
+	valCurs, err := DecodeFile(filepath.Join("..", "..", "testdata", "charsets", "undeclared-windows-1251.xml"),
+		DefaultOptions())
+	if err == nil && valCurs.Valutes[0].Name == "Доллар США" {
+		t.Fatal("undeclared windows-1251 decoded correctly without a charset override")
+	}
+}
+
+// oneShotDecode is the decoder the stream replaced: it unmarshals the whole
+// document with a single Decode call.
+func oneShotDecode(t *testing.T, path string, opts Options) *data.ValCurs {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	defer file.Close()
+
+	decoder, err := newXMLDecoder(file, opts)
+	if err != nil {
+		t.Fatalf("newXMLDecoder: %v", err)
+	}
+
+	var document struct {
+		Date    string      `xml:"Date,attr"`
+		Name    string      `xml:"name,attr"`
+		Valutes []rawValute `xml:"Valute"`
+	}
+
+	if err := decoder.Decode(&document); err != nil {
+		t.Fatalf("decoding %s in one call: %v", path, err)
+	}
+
+	result := &data.ValCurs{Date: document.Date, Name: document.Name, Valutes: nil}
+
+	for index, raw := range document.Valutes {
+		valute, err := raw.toValute(opts)
+		if err != nil {
+			t.Fatalf("converting Valute %s: %v", raw.ID, err)
+		}
+
+		valute.FillUnitRate()
+		valute.Source = path
+		valute.Index = index
+		valute.Date = document.Date
+		result.Valutes = append(result.Valutes, valute)
+	}
+
+	return result
+}
+
+func TestStreamMatchesOneShotDecode(t *testing.T) {
+	t.Parallel()
+
+	paths, err := filepath.Glob(filepath.Join("..", "..", "testdata", "charsets", "*.xml"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("listing the charset corpus: %v", err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			t.Parallel()
+
+			opts := DefaultOptions()
+			if strings.HasPrefix(filepath.Base(path), "undeclared-") {
+				opts.Charset = "windows-1251"
+			}
+
+			want := oneShotDecode(t, path, opts)
+
+			streamed, err := DecodeFile(path, opts)
+			if err != nil {
+				t.Fatalf("DecodeFile: %v", err)
+			}
+
+			var walked data.CurrencyList
+
+			if _, err := Walk(path, opts, func(valute data.Valute) error {
+				walked = append(walked, valute)
+
+				return nil
+			}); err != nil {
+				t.Fatalf("Walk: %v", err)
+			}
+
+			if streamed.Date != want.Date || streamed.Name != want.Name {
+				t.Errorf("header = %q/%q, want %q/%q", streamed.Date, streamed.Name, want.Date, want.Name)
+			}
+
+			if !reflect.DeepEqual(streamed.Valutes, want.Valutes) {
+				t.Errorf("DecodeFile valutes = %+v, want %+v", streamed.Valutes, want.Valutes)
+			}
+
+			if !reflect.DeepEqual(walked, want.Valutes) {
+				t.Errorf("Walk valutes = %+v, want %+v", walked, want.Valutes)
+			}
+		})
+	}
+}
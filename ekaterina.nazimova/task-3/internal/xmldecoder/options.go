@@ -0,0 +1,127 @@
+package xmldecoder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/numlocale"
+)
+
+type Mode int
+
+const (
+	// ModeStrict fails the whole decode on the first malformed Valute.
+	ModeStrict Mode = iota
+	// ModeLenient skips malformed Valute elements and records them as issues.
+	ModeLenient
+)
+
+var ErrUnknownMode = errors.New("unknown decode mode")
+
+func ParseMode(name string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "strict":
+		return ModeStrict, nil
+	case "lenient":
+		return ModeLenient, nil
+	default:
+		return ModeStrict, fmt.Errorf("%w %q (expected strict or lenient)", ErrUnknownMode, name)
+	}
+}
+
+func (m Mode) String() string {
+	if m == ModeLenient {
+		return "lenient"
+	}
+
+	return "strict"
+}
+
+type Options struct {
+	Locale  numlocale.Locale
+	Charset string
+	Mode    Mode
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Locale:  numlocale.Russian(),
+		Charset: "",
+		Mode:    ModeStrict,
+	}
+}
+
+// Issue describes a Valute element that was skipped in lenient mode. Index
+// is the 0-based position of the element among the Valute elements.
+type Issue struct {
+	Source string
+	Index  int
+	ID     string
+	Reason string
+}
+
+type rawValute struct {
+	ID        string `xml:"ID,attr"`
+	NumCode   string `xml:"NumCode"`
+	CharCode  string `xml:"CharCode"`
+	Nominal   string `xml:"Nominal"`
+	Name      string `xml:"Name"`
+	Value     string `xml:"Value"`
+	VunitRate string `xml:"VunitRate"`
+}
+
+func (r rawValute) toValute(opts Options) (data.Valute, error) {
+	numCode, err := parseInteger(r.NumCode, opts)
+	if err != nil {
+		return data.Valute{}, fmt.Errorf("parsing NumCode: %w", err)
+	}
+
+	nominal, err := parseInteger(r.Nominal, opts)
+	if err != nil {
+		return data.Valute{}, fmt.Errorf("parsing Nominal: %w", err)
+	}
+
+	value, err := data.ParseLocalizedCurrencyValue(r.Value, opts.Locale)
+	if err != nil {
+		return data.Valute{}, fmt.Errorf("parsing Value: %w", err)
+	}
+
+	unitRate := data.CurrencyValue{}
+
+	if r.VunitRate != "" {
+		if unitRate, err = data.ParseLocalizedCurrencyValue(r.VunitRate, opts.Locale); err != nil {
+			return data.Valute{}, fmt.Errorf("parsing VunitRate: %w", err)
+		}
+	}
+
+	return data.Valute{
+		ID:       r.ID,
+		Nominal:  nominal,
+		Name:     r.Name,
+		CharCode: r.CharCode,
+		NumCode:  numCode,
+		Value:    value,
+		UnitRate: unitRate,
+	}, nil
+}
+
+func parseInteger(text string, opts Options) (int, error) {
+	if text == "" {
+		return 0, nil
+	}
+
+	canonical, err := opts.Locale.Normalize(text)
+	if err != nil {
+		return 0, fmt.Errorf("normalizing integer: %w", err)
+	}
+
+	parsed, err := strconv.Atoi(canonical)
+	if err != nil {
+		return 0, fmt.Errorf("parsing integer: %w", err)
+	}
+
+	return parsed, nil
+}
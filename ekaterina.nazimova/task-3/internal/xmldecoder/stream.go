@@ -0,0 +1,223 @@
+package xmldecoder
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/UwUshkin/task-3/internal/data"
+	"github.com/UwUshkin/task-3/internal/source"
+)
+
+const valuteElement = "Valute"
+
+type Stream struct {
+	decoder *xml.Decoder
+	closer  io.Closer
+	opts    Options
+	source  string
+
+	header  data.ValCurs
+	started bool
+	done    bool
+
+	current data.Valute
+	index   int
+	issues  []Issue
+	err     error
+}
+
+func NewStreamWithOptions(reader io.Reader, opts Options) *Stream {
+	decoder, err := newXMLDecoder(reader, opts)
+
+	return &Stream{
+		decoder: decoder,
+		closer:  nil,
+		opts:    opts,
+		source:  "",
+		header:  data.ValCurs{Date: "", Name: "", Valutes: nil},
+		started: false,
+		done:    false,
+		current: data.Valute{},
+		index:   -1,
+		issues:  nil,
+		err:     err,
+	}
+}
+
+func OpenDocument(document source.Document, opts Options) (*Stream, error) {
+	reader, err := document.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	stream := NewStreamWithOptions(reader, opts)
+	stream.closer = reader
+	stream.source = document.Name
+
+	return stream, nil
+}
+
+func (s *Stream) Next() bool {
+	if s.done || s.err != nil {
+		return false
+	}
+
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			s.fail(err)
+
+			return false
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if !s.started {
+				s.readHeader(element)
+
+				continue
+			}
+
+			if element.Name.Local != valuteElement {
+				if err := s.decoder.Skip(); err != nil {
+					s.fail(err)
+
+					return false
+				}
+
+				continue
+			}
+
+			if s.decodeValute(element) {
+				return true
+			}
+
+			if s.err != nil {
+				return false
+			}
+		case xml.EndElement:
+			s.done = true
+
+			return false
+		}
+	}
+}
+
+func (s *Stream) Valute() data.Valute {
+	return s.current
+}
+
+func (s *Stream) Header() *data.ValCurs {
+	header := s.header
+
+	return &header
+}
+
+// Issues lists the Valute elements skipped so far in lenient mode.
+func (s *Stream) Issues() []Issue {
+	return s.issues
+}
+
+func (s *Stream) Err() error {
+	return s.err
+}
+
+func (s *Stream) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	if err := s.closer.Close(); err != nil {
+		return fmt.Errorf("closing XML stream: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Stream) readHeader(root xml.StartElement) {
+	s.started = true
+
+	for _, attr := range root.Attr {
+		switch attr.Name.Local {
+		case "Date":
+			s.header.Date = attr.Value
+		case "name":
+			s.header.Name = attr.Value
+		}
+	}
+}
+
+func (s *Stream) decodeValute(start xml.StartElement) bool {
+	s.index++
+
+	var raw rawValute
+	if err := s.decoder.DecodeElement(&raw, &start); err != nil {
+		s.failAt(s.index, err)
+
+		return false
+	}
+
+	valute, err := raw.toValute(s.opts)
+	if err != nil {
+		if s.opts.Mode == ModeLenient {
+			s.issues = append(s.issues, Issue{Source: s.source, Index: s.index, ID: raw.ID, Reason: err.Error()})
+
+			return false
+		}
+
+		s.failAt(s.index, err)
+
+		return false
+	}
+
+	valute.FillUnitRate()
+	valute.Source = s.source
+	valute.Index = s.index
+	valute.Date = s.header.Date
+
+	s.current = valute
+
+	return true
+}
+
+func (s *Stream) fail(err error) {
+	s.failAt(s.index+1, err)
+}
+
+func (s *Stream) failAt(index int, err error) {
+	if errors.Is(err, io.EOF) && s.started {
+		err = io.ErrUnexpectedEOF
+	}
+
+	line, column := s.decoder.InputPos()
+
+	s.err = &DecodeError{
+		Source: s.source,
+		Index:  index,
+		Line:   line,
+		Column: column,
+		Offset: s.decoder.InputOffset(),
+		Err:    err,
+	}
+}
+
+// DecodeError reports where in the XML input decoding stopped. Line and
+// Column are 1-based, Offset is the byte offset into the decoded text.
+type DecodeError struct {
+	Source string
+	Index  int
+	Line   int
+	Column int
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("reading Valute at index %d (line %d, column %d): %v", e.Index, e.Line, e.Column, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}